@@ -1,9 +1,21 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/spf13/viper"
+)
 
 type Config struct {
 	DBFilePath string `mapstructure:"DB_FILEPATH"`
+	// directory that tables exported from the UI are written to; defaults to the working directory
+	ExportDir string `mapstructure:"EXPORT_DIR"`
+	// groups of playlists that the Starred search and duplicate finder can be run against,
+	// read from the PLAYLIST_GROUP_* keys
+	PlaylistGroups []models.PlaylistGroup `mapstructure:"-"`
+	// keys bound to each UI action, read from the KEY_* keys on top of the defaults
+	Keymap models.Keymap `mapstructure:"-"`
+	// colors of the UI, read from the THEME and THEME_* keys, or monochrome if NO_COLOR is set
+	Theme models.Theme `mapstructure:"-"`
 }
 
 // Read configuration file and map it to a Config struct
@@ -21,5 +33,29 @@ func SetConfig() Config {
 		panic(err)
 	}
 
+	groups, err := playlistGroups()
+
+	if err != nil {
+		panic(err)
+	}
+
+	configuration.PlaylistGroups = groups
+
+	keys, err := keymap()
+
+	if err != nil {
+		panic(err)
+	}
+
+	configuration.Keymap = keys
+
+	colors, err := theme()
+
+	if err != nil {
+		panic(err)
+	}
+
+	configuration.Theme = colors
+
 	return configuration
 }
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/spf13/viper"
+)
+
+// Playlist groups are configured with keys of the form PLAYLIST_GROUP_<NAME>_<KIND>, e.g.
+//
+//	PLAYLIST_GROUP_FAVOURITES_LIKE=Fav -%;Best of%
+//	PLAYLIST_GROUP_FAVOURITES_REGEX=^\d{4} Archive$
+//	PLAYLIST_GROUP_ROAD_TRIP_IDS=37i9dQZF1DX9wC1KY45plY;37i9dQZF1DWSThc8QnxalT
+//
+// LIKE is a ';'-separated list of SQL LIKE patterns, REGEX is a regular expression
+// and IDS is a ';'-separated list of playlist ids, all matched against the playlists in the DB.
+// The group's name is <NAME> in title case with underscores replaced by spaces.
+const playlistGroupPrefix = "playlist_group_"
+
+// Read the playlist groups from the config, sorted by name.
+// If there are none, the only group is models.DefaultPlaylistGroup.
+func playlistGroups() ([]models.PlaylistGroup, error) {
+	groups := make(map[string]*models.PlaylistGroup)
+
+	group := func(name string) *models.PlaylistGroup {
+		if groups[name] == nil {
+			groups[name] = &models.PlaylistGroup{Name: strings.Title(strings.ReplaceAll(name, "_", " "))}
+		}
+
+		return groups[name]
+	}
+
+	// viper lower-cases keys
+	for _, key := range viper.AllKeys() {
+		if !strings.HasPrefix(key, playlistGroupPrefix) {
+			continue
+		}
+
+		value := viper.GetString(key)
+		name := strings.TrimPrefix(key, playlistGroupPrefix)
+
+		switch {
+		case strings.HasSuffix(name, "_like"):
+			g := group(strings.TrimSuffix(name, "_like"))
+			for _, pattern := range splitList(value) {
+				g.Patterns = append(g.Patterns, models.LikePattern(pattern))
+			}
+		case strings.HasSuffix(name, "_regex"):
+			pattern, err := regexp.Compile(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", strings.ToUpper(key), err)
+			}
+
+			g := group(strings.TrimSuffix(name, "_regex"))
+			g.Patterns = append(g.Patterns, pattern)
+		case strings.HasSuffix(name, "_ids"):
+			g := group(strings.TrimSuffix(name, "_ids"))
+			g.PlaylistIds = append(g.PlaylistIds, splitList(value)...)
+		default:
+			return nil, fmt.Errorf("unknown key %s, expected it to end in _LIKE, _REGEX or _IDS", strings.ToUpper(key))
+		}
+	}
+
+	if len(groups) == 0 {
+		return []models.PlaylistGroup{models.DefaultPlaylistGroup}, nil
+	}
+
+	var sorted []models.PlaylistGroup
+	for _, g := range groups {
+		sorted = append(sorted, *g)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	return sorted, nil
+}
+
+// Split a ';'-separated list, dropping blank items
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
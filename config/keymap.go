@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/spf13/viper"
+)
+
+// Key bindings are configured with keys of the form KEY_<ACTION>, e.g.
+//
+//	KEY_MENU_STARRED=h
+//	KEY_LIST_NEXT=n;ctrl+n
+//	KEY_HELP=f1
+//
+// The value is a ';'-separated list of keys, which replace the Action's default keys.
+// The Actions and their defaults are listed in models.Actions.
+const keyPrefix = "key_"
+
+// Read the key bindings from the config, on top of the defaults.
+// Returns an error if a key is invalid or bound to two Actions that can be used on the same screen.
+func keymap() (models.Keymap, error) {
+	km := models.DefaultKeymap()
+
+	// viper lower-cases keys
+	for _, configKey := range viper.AllKeys() {
+		if !strings.HasPrefix(configKey, keyPrefix) {
+			continue
+		}
+
+		info, ok := models.FindAction(strings.TrimPrefix(configKey, keyPrefix))
+
+		if !ok {
+			return nil, fmt.Errorf("unknown key binding %s", strings.ToUpper(configKey))
+		}
+
+		var keys []models.Key
+
+		for _, spec := range splitList(viper.GetString(configKey)) {
+			key, err := models.ParseKey(spec)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", strings.ToUpper(configKey), err)
+			}
+
+			keys = append(keys, key)
+		}
+
+		km[info.Action] = keys
+	}
+
+	return km, km.Validate()
+}
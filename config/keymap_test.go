@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/spf13/viper"
+)
+
+func TestKeymap(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		// the keys of the Actions that the config changes
+		want    map[models.Action]string
+		wantErr string
+	}{
+		{name: "defaults", want: map[models.Action]string{models.ActionExport: "e", models.ActionBack: "alt+left, alt+b"}},
+		{
+			name:   "a single key replaces the defaults",
+			config: map[string]string{"key_back": "ctrl+b"},
+			want:   map[models.Action]string{models.ActionBack: "ctrl+b", models.ActionForward: "alt+right, alt+f"},
+		},
+		{
+			name:   "a list of keys, with spaces and an empty item",
+			config: map[string]string{"key_list_next": " n ; ctrl+n ;"},
+			want:   map[models.Action]string{models.ActionListNext: "n, ctrl+n"},
+		},
+		{
+			name:   "moving a key from one Action to another",
+			config: map[string]string{"key_export": "x", "key_sort": "e"},
+			want:   map[models.Action]string{models.ActionExport: "x", models.ActionSort: "e"},
+		},
+		{
+			name:    "an unknown Action",
+			config:  map[string]string{"key_nope": "x"},
+			wantErr: "unknown key binding KEY_NOPE",
+		},
+		{
+			name:    "an invalid key",
+			config:  map[string]string{"key_export": "shift+e"},
+			wantErr: "invalid KEY_EXPORT: invalid key 'shift+e'",
+		},
+		{
+			name:    "a conflict",
+			config:  map[string]string{"key_export": "s"},
+			wantErr: "KEY_EXPORT and KEY_SORT are both bound to 's'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			for k, v := range tt.config {
+				viper.Set(k, v)
+			}
+
+			km, err := keymap()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("keymap() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("keymap() returned error: %v", err)
+			}
+
+			for action, want := range tt.want {
+				if got := km.Label(action); got != want {
+					t.Errorf("keys of %s = %q, want %q", action, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/spf13/viper"
+)
+
+// The UI's colors are configured with THEME, naming one of models.Themes, and keys of the form THEME_<COLOR>, e.g.
+//
+//	THEME=light
+//	THEME_HEADER=navy
+//	THEME_SELECTED_BACKGROUND=#005f87
+//
+// THEME_<COLOR> replaces one of the theme's colors with a W3C color name, a #rrggbb value or "default",
+// the terminal's own color. The colors are listed in models.Theme.Colors.
+// If the NO_COLOR environment variable is set, the monochrome theme is used and the THEME keys are ignored.
+const (
+	themeKey         = "theme"
+	themeColorPrefix = "theme_"
+)
+
+// Read the theme from the config.
+// Returns an error if the theme or a color is unknown.
+func theme() (models.Theme, error) {
+	// see https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		return models.Themes["monochrome"], nil
+	}
+
+	name := models.DefaultTheme
+	if viper.IsSet(themeKey) {
+		name = strings.ToLower(viper.GetString(themeKey))
+	}
+
+	theme, ok := models.Themes[name]
+
+	if !ok {
+		return theme, fmt.Errorf("unknown THEME '%s', expected one of %s", name, strings.Join(models.ThemeNames(), ", "))
+	}
+
+	colors := theme.Colors()
+
+	// viper lower-cases keys
+	for _, key := range viper.AllKeys() {
+		if !strings.HasPrefix(key, themeColorPrefix) {
+			continue
+		}
+
+		color, ok := colors[strings.TrimPrefix(key, themeColorPrefix)]
+
+		if !ok {
+			return theme, fmt.Errorf("unknown theme color %s", strings.ToUpper(key))
+		}
+
+		value := strings.ToLower(strings.TrimSpace(viper.GetString(key)))
+
+		if value == "default" {
+			*color = tcell.ColorDefault
+			continue
+		}
+
+		// GetColor returns ColorDefault for anything it can't read
+		if *color = tcell.GetColor(value); *color == tcell.ColorDefault {
+			return theme, fmt.Errorf("invalid %s: unknown color '%s'", strings.ToUpper(key), value)
+		}
+	}
+
+	return theme, nil
+}
@@ -1,24 +1,154 @@
 package internal
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
 	"github.com/ccb012100/go-playlist-search/internal/models"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
 func SearchForAlbums(v *models.View) {
-	box := tview.NewBox().SetBorder(true).SetTitle("Albums")
-	box.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
-		switch e.Key() {
-		case tcell.KeyESC:
-			GoToMainMenu(v)
+	v.UpdateTitleBar("Search Albums")
+
+	input := tview.NewInputField()
+	// TODO: set minimum input length
+	input.SetLabel("Search for albums: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			v.Back()
+		case tcell.KeyEnter:
+			ShowAlbumSearchResults(v, input.GetText())
 		}
+	})
+
+	v.PushScreen("album-search", input, searchKeys...)
+}
+
+func ShowAlbumSearchResults(v *models.View, query string) {
+	v.UpdateTitleBar(fmt.Sprintf("Albums matching '%s'", query))
+
+	albums, err := data.SearchAlbums(context.Background(), query, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search Albums: %w", err))
+		return
+	}
+
+	v.UpdateMessageBar(fmt.Sprintf("Found %d Albums matching '%s'", len(albums), query))
 
-		return e
+	// show message if 0 results
+	if len(albums) == 0 {
+		textView := tview.NewTextView().SetDynamicColors(true)
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no Albums matching %s", colorText(v.Theme.Cell, "b", query)))
+
+		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+			switch e.Key() {
+			case tcell.KeyESC:
+				v.PopScreen()
+			}
+
+			return e
+		})
+
+		v.PushScreen("no-results", textView, escKeys...)
+		return
+	}
+
+	// if there's only 1 match, just select it
+	if len(albums) == 1 {
+		SelectAlbum(v, albums[0].Id, albums[0].Name)
+		return
+	}
+
+	displayAlbums(v, albums)
+}
+
+func displayAlbums(v *models.View, albums []models.Album) {
+	v.List = NewList(v)
+	for _, album := range albums {
+		a := album
+		v.List.AddItem(a.Name, fmt.Sprintf("%s %s", a.Artists, colorText(v.Theme.Muted, "", fmt.Sprintf("(%s, %s)", a.ReleaseYear(), a.AlbumType))), 0, func() { SelectAlbum(v, a.Id, a.Name) })
+	}
+	AddQuitToHomeOption(v.List, v)
+
+	AddResetOption(v, v.List, func() {
+		SearchForAlbums(v)
 	})
-	v.SetMainPanel(box)
+
+	v.List.SetTitle("Album results")
+
+	v.PushScreen("album-results", v.List, screenKeys(listKeys, quitToMenuKeys, resetKeys)...)
 }
 
 func SelectAlbum(v *models.View, id string, name string) {
+	v.UpdateTitleBar(name)
+	v.UpdateMessageBar(fmt.Sprintf("Selected album %s %s", id, name))
+
+	album, err := data.GetAlbum(id, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Album: %w", err))
+		return
+	}
+
+	tracks, err := data.GetAlbumTracks(id, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Album tracks: %w", err))
+		return
+	}
+
+	displayAlbumTracks(v, album, tracks)
+}
+
+func displayAlbumTracks(v *models.View, album models.Album, tracks []models.Track) {
+	saved := 0
+	for _, t := range tracks {
+		if t.Playlists != "" {
+			saved++
+		}
+	}
+
+	header := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	header.SetText(fmt.Sprintf("%s by %s\n%s (%s) | %d tracks, %d in playlists",
+		colorText(v.Theme.Header, "b", tview.Escape(album.Name)), tview.Escape(album.Artists), album.ReleaseDate, album.AlbumType, len(tracks), saved))
+
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("#").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+	table.SetCell(0, 1, tview.NewTableCell("Track").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 2, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Playlists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+
+	// set table contents
+	for i := 0; i < len(tracks); i++ {
+		track := tracks[i]
+
+		// tracks that aren't in any playlist are dimmed
+		color := v.Theme.Cell
+		playlists := track.Playlists
+		if playlists == "" {
+			color = v.Theme.Muted
+			playlists = "-"
+		}
+
+		// use i+1 to offset for header
+		table.SetCell(i+1, 0, tview.NewTableCell(padRight(strconv.Itoa(track.TrackNumber))).SetTextColor(color).SetAlign(tview.AlignRight).SetExpansion(0))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(track.Name)).SetTextColor(color).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(track.Artists)).SetTextColor(color).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(playlists)).SetTextColor(color).SetAlign(tview.AlignLeft).SetExpansion(2))
+	}
+
+	table.SetInputCapture(BackFunc(v))
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 2, 0, false).
+		AddItem(table, 0, 1, true)
 
+	v.PushScreen("album", flex, backKeys...)
 }
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/ccb012100/go-playlist-search/internal/data"
@@ -11,43 +13,72 @@ import (
 )
 
 func SearchForArtists(v *models.View) {
+	v.UpdateTitleBar("Search Artists")
+
 	input := tview.NewInputField()
-	// TODO: set minimum input length
-	input.SetLabel("Search for artists: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
-		v.UpdateMessageBar(fmt.Sprintf("key = %v", key))
+	results := newList(v).ShowSecondaryText(false)
+	results.SetBorder(true).SetTitle("Matching Artists")
+	results.SetInputCapture(backToInputFunc(v, input))
+
+	search := newLiveSearch(v.App, func(ctx context.Context, query string) func() {
+		artists, err := data.SearchArtists(ctx, query, v.DB)
+
+		return func() {
+			if err != nil {
+				v.ShowError(fmt.Errorf("could not search Artists: %w", err))
+				return
+			}
+
+			results.Clear().SetTitle(fmt.Sprintf("%d Artists matching '%s'", len(artists), query))
+			for _, artist := range artists {
+				a := artist
+				results.AddItem(a.Name, a.Id, 0, func() { SelectArtist(v, a) })
+			}
+		}
+	}, func() { results.Clear().SetTitle("Matching Artists") })
+
+	input.SetLabel("Search for artists: ").SetFieldWidth(50).SetChangedFunc(search.Update).SetDoneFunc(func(key tcell.Key) {
 		switch key {
 		case tcell.KeyEscape:
-			GoToMainMenu(v)
+			search.Stop()
+			v.Back()
 		case tcell.KeyEnter:
+			search.Stop()
 			ShowArtistSearchResults(v, input.GetText())
 		}
 	})
 
-	v.SetMainPanel(input)
+	v.PushScreen("artist-search", liveSearchLayout(v, input, results), screenKeys(liveSearchKeys, openRowKeys)...)
 }
 
 func ShowArtistSearchResults(v *models.View, query string) {
-	v.UpdateMessageBar(fmt.Sprintf("func ShowArtists() query='%s'", query))
 	v.UpdateTitleBar(fmt.Sprintf("Artists matching '%s'", query))
 
-	artists := data.SearchArtists(query, v.DB)
+	artists, err := data.SearchArtists(context.Background(), query, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search Artists: %w", err))
+		return
+	}
+
+	v.UpdateMessageBar(fmt.Sprintf("Found %d Artists matching '%s'", len(artists), query))
 
 	// show message if 0 results
 	if len(artists) == 0 {
 		textView := tview.NewTextView().SetDynamicColors(true)
-		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(tcell.ColorDarkRed)
-		textView.SetText(fmt.Sprintf("There are no Artists matching [green:-:b]%s[-]", query))
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no Artists matching %s", colorText(v.Theme.Cell, "b", query)))
 
 		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
 			switch e.Key() {
 			case tcell.KeyESC:
-				v.SetMainPanel(v.List)
+				v.PopScreen()
 			}
 
 			return e
 		})
 
-		v.SetMainPanel(textView)
+		v.PushScreen("no-results", textView, escKeys...)
 		return
 	}
 
@@ -61,59 +92,66 @@ func ShowArtistSearchResults(v *models.View, query string) {
 }
 
 func displayArtists(v *models.View, artists []models.SimpleIdentifier) {
-	v.List.Clear()
+	v.List = NewList(v)
 	for _, artist := range artists {
 		a := artist
 		v.List.AddItem(artist.Name, artist.Id, 0, func() { SelectArtist(v, a) })
 	}
 	AddQuitToHomeOption(v.List, v)
 
-	AddResetOption(v.List, func() {
+	AddResetOption(v, v.List, func() {
 		SearchForArtists(v)
 	})
 
 	v.List.SetTitle("Artists results")
 
-	v.SetMainPanel(v.List)
+	v.PushScreen("artist-results", v.List, screenKeys(listKeys, quitToMenuKeys, resetKeys)...)
 }
 
 func SelectArtist(v *models.View, artist models.SimpleIdentifier) {
 	v.UpdateTitleBar(artist.Name)
 	v.UpdateMessageBar(fmt.Sprintf("Selected artist %s %s", artist.Id, artist.Name))
 
-	v.List.Clear().
+	v.List = NewList(v)
+	v.List.
 		AddItem("Albums", "View Artist's Albums", '1', func() { ShowArtistAlbums(v, artist) }).
-		AddItem("Tracks", "View Artist's Tracks", '2', func() { v.UpdateMessageBar("Not implemented yet") }).
-		AddItem("Playlists", "List Playlists containing the Artist", '3', func() { showPlaylistsWithArtist(v, artist) })
+		AddItem("Tracks", "View Artist's Tracks", '2', func() { ShowArtistTracks(v, artist) }).
+		AddItem("Playlists", "List Playlists containing the Artist", '3', func() { showPlaylistsWithArtist(v, artist) }).
+		AddItem("Collaborators", "List Artists who appear on the Artist's Tracks", '4', func() { showArtistCollaborators(v, artist) })
 
-	AddQuitOption(v.List, func() { GoToMainMenu(v) })
+	AddQuitOption(v, v.List, func() { GoToMainMenu(v) })
 
-	v.List.SetTitle("Artist Info").SetBorderColor(tcell.ColorDarkSeaGreen)
+	v.List.SetTitle("Artist Info").SetBorderColor(v.Theme.MenuBorder)
 
-	v.SetMainPanel(v.List)
+	v.PushScreen("artist", v.List, screenKeys(listKeys, quitToMenuKeys)...)
 }
 
 func ShowArtistAlbums(v *models.View, artist models.SimpleIdentifier) {
 	v.UpdateTitleBar(fmt.Sprintf("Albums by %s", artist.Name))
 
-	albums := data.GetAlbumsByArtist(&artist, v.DB)
+	albums, err := data.GetAlbumsByArtist(&artist, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Artist albums: %w", err))
+		return
+	}
 
 	// Display message if there are no albums found
 	if len(albums) == 0 {
 		textView := tview.NewTextView().SetDynamicColors(true)
-		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(tcell.ColorDarkRed)
-		textView.SetText(fmt.Sprintf("There are no Albums for artist [green:-:b]%s[-] [gray:-:-](Id = %s)[-]", artist.Name, artist.Id))
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no Albums for artist %s %s", colorText(v.Theme.Cell, "b", artist.Name), colorText(v.Theme.Muted, "", fmt.Sprintf("(Id = %s)", artist.Id))))
 
 		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
 			switch e.Key() {
 			case tcell.KeyESC:
-				v.SetMainPanel(v.List)
+				v.PopScreen()
 			}
 
 			return e
 		})
 
-		v.SetMainPanel(textView)
+		v.PushScreen("no-results", textView, escKeys...)
 		return
 	}
 
@@ -121,38 +159,168 @@ func ShowArtistAlbums(v *models.View, artist models.SimpleIdentifier) {
 }
 
 func displayArtistAlbumsTable(v *models.View, albums []models.Album) {
-	table := tview.NewTable().SetBorders(true)
+	table := newTable(v).SetBorders(true)
 	// set header row
-	table.SetCell(0, 0, tview.NewTableCell("Name").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(2))
-	table.SetCell(0, 1, tview.NewTableCell("Tracks").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 2, tview.NewTableCell("Release Date").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(2))
-	table.SetCell(0, 3, tview.NewTableCell("Type").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 0, tview.NewTableCell("Name").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 1, tview.NewTableCell("Tracks").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 2, tview.NewTableCell("Release Date").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Type").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
 
 	// set table contents
 	for i := 0; i < len(albums); i++ {
 		album := albums[i]
 
 		// use i+1 to offset for header
-		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(album.Name)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(2))
-		table.SetCell(i+1, 1, tview.NewTableCell(padRight(strconv.Itoa(album.TotalTracks))).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignRight).SetExpansion(1))
-		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(album.ReleaseDate)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(2))
-		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(album.AlbumType)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
+		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(album.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 1, tview.NewTableCell(padRight(strconv.Itoa(album.TotalTracks))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(1))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(album.ReleaseDate)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(album.AlbumType)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
 	}
 
-	table.SetInputCapture(BackToViewListFunc(v))
+	// open the Album when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectAlbum(v, albums[row-1].Id, albums[row-1].Name)
+		}
+	})
 
-	v.SetMainPanel(table)
+	// the Export action exports the Albums, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{name: "albums", columns: models.Album{}.Columns(), records: models.AlbumRecords(albums)}
+	}, BackFunc(v)))
+
+	v.PushScreen("artist-albums", table, screenKeys(openRowKeys, exportKeys, backKeys)...)
+}
+
+func ShowArtistTracks(v *models.View, artist models.SimpleIdentifier) {
+	v.UpdateTitleBar(fmt.Sprintf("Tracks by %s", artist.Name))
+
+	tracks, err := data.GetTracksByArtist(&artist, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Artist tracks: %w", err))
+		return
+	}
+
+	// Display message if there are no tracks found
+	if len(tracks) == 0 {
+		textView := tview.NewTextView().SetDynamicColors(true)
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no Tracks for artist %s %s", colorText(v.Theme.Cell, "b", artist.Name), colorText(v.Theme.Muted, "", fmt.Sprintf("(Id = %s)", artist.Id))))
+
+		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+			switch e.Key() {
+			case tcell.KeyESC:
+				v.PopScreen()
+			}
+
+			return e
+		})
+
+		v.PushScreen("no-results", textView, escKeys...)
+		return
+	}
+
+	displayArtistTracksTable(v, artist, tracks)
+}
+
+// orders that the artist tracks table can be cycled through
+var artistTrackSorts = []struct {
+	name string
+	sort func([]models.Track)
+}{
+	{"Album", func(t []models.Track) { sort.Stable(models.TracksByAlbum(t)) }},
+	{"Release Date", func(t []models.Track) { sort.Stable(models.TracksByReleaseDate(t)) }},
+	{"Track Name", func(t []models.Track) { sort.Stable(models.TracksByName(t)) }},
+}
+
+// keys of the artist tracks table, other than opening rows, exporting and going back, for its help
+var artistTracksKeys = []models.ScreenAction{{Action: models.ActionSort}}
+
+func displayArtistTracksTable(v *models.View, artist models.SimpleIdentifier, tracks []models.Track) {
+	table := newTable(v).SetBorders(true)
+	sortIndex := 0
+
+	fillTable := func() {
+		artistTrackSorts[sortIndex].sort(tracks)
+
+		table.Clear()
+		table.SetTitle(fmt.Sprintf("%d tracks, sorted by %s (press %s to change)", len(tracks), artistTrackSorts[sortIndex].name, v.Keymap.Label(models.ActionSort)))
+
+		// set header row
+		table.SetCell(0, 0, tview.NewTableCell("Album").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+		table.SetCell(0, 1, tview.NewTableCell("#").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+		table.SetCell(0, 2, tview.NewTableCell("Track").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+		table.SetCell(0, 3, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+		table.SetCell(0, 4, tview.NewTableCell("Playlists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+
+		// set table contents
+		for i := 0; i < len(tracks); i++ {
+			track := tracks[i]
+
+			// use i+1 to offset for header
+			table.SetCell(i+1, 0, tview.NewTableCell(padLeft(track.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(i+1, 1, tview.NewTableCell(padRight(strconv.Itoa(track.TrackNumber))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(0))
+			table.SetCell(i+1, 2, tview.NewTableCell(padLeft(track.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(i+1, 3, tview.NewTableCell(padLeft(track.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(i+1, 4, tview.NewTableCell(padLeft(track.Playlists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		}
+
+		table.Select(1, 0)
+	}
+
+	fillTable()
+
+	// open the Song when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectSong(v, tracks[row-1].Id, tracks[row-1].Name)
+		}
+	})
+
+	back := BackFunc(v)
+	// the Export action exports the tracks in their current order
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{
+			name:    "artist-tracks",
+			columns: models.Track{}.Columns(),
+			records: models.TrackRecords(tracks),
+			entries: models.TrackEntries(tracks),
+			title:   fmt.Sprintf("Tracks by %s", artist.Name),
+		}
+	}, func(e *tcell.EventKey) *tcell.EventKey {
+		// cycle the sort order instead of leaving the table
+		if v.Keymap.Matches(models.ActionSort, e) {
+			sortIndex = (sortIndex + 1) % len(artistTrackSorts)
+			fillTable()
+			return nil
+		}
+
+		return back(e)
+	}))
+
+	table.SetBorder(true)
+
+	v.PushScreen("artist-tracks", table, screenKeys(openRowKeys, artistTracksKeys, exportKeys, backKeys)...)
 }
 
 // Display Playlists containing the specified Artist
 func showPlaylistsWithArtist(v *models.View, artist models.SimpleIdentifier) {
 	v.UpdateTitleBar("Playlists containing tracks by " + artist.Name)
 
-	playlists := data.FindPlaylistsContainingArtist(artist, v.DB)
+	playlists, err := data.FindPlaylistsContainingArtist(artist, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Playlists containing Artist: %w", err))
+		return
+	}
 
-	// this should never happen
+	// the Artist can be on an Album without any of its Tracks being in a Playlist
 	if len(playlists) == 0 {
-		panic(fmt.Sprintf("No playlists were found for artist '%s', '%s'", artist.Name, artist.Id))
+		v.UpdateMessageBar(fmt.Sprintf("No playlists were found for artist '%s', '%s'", artist.Name, artist.Id))
+		return
 	}
 
 	txt := fmt.Sprintf("Playlists containing %s:\n", artist.Name)
@@ -163,7 +331,53 @@ func showPlaylistsWithArtist(v *models.View, artist models.SimpleIdentifier) {
 
 	textView := tview.NewTextView().SetDynamicColors(true)
 	textView.SetText(txt)
-	textView.SetInputCapture(BackToViewListFunc(v))
+	textView.SetInputCapture(BackFunc(v))
+
+	v.PushScreen("artist-playlists", textView, backKeys...)
+}
+
+// List the artists credited on the same tracks as the artist; selecting one shows their Artist Info,
+// so the collaborations can be followed from artist to artist
+func showArtistCollaborators(v *models.View, artist models.SimpleIdentifier) {
+	v.UpdateTitleBar("Artists who appear with " + artist.Name)
+
+	collaborators, err := data.GetCollaborators(artist, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Artist collaborators: %w", err))
+		return
+	}
+
+	if len(collaborators) == 0 {
+		v.UpdateMessageBar(fmt.Sprintf("No collaborators were found for artist '%s', '%s'", artist.Name, artist.Id))
+		return
+	}
+
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("Artist").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 1, tview.NewTableCell("Shared Tracks").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 2, tview.NewTableCell("Tracks").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(4))
+
+	// set table contents
+	for i, c := range collaborators {
+		// use i+1 to offset for header
+		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(c.Artist.Name)).SetTextColor(v.Theme.Cell))
+		table.SetCell(i+1, 1, tview.NewTableCell(padRight(strconv.Itoa(c.SharedTracks))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(c.Tracks)).SetTextColor(v.Theme.Muted))
+	}
+
+	// open the collaborator's Artist Info when their row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		if row > 0 {
+			SelectArtist(v, collaborators[row-1].Artist)
+		}
+	})
+
+	// the Export action exports the collaborators, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{name: "collaborators", columns: models.Collaborator{}.Columns(), records: models.CollaboratorRecords(collaborators)}
+	}, BackFunc(v)))
 
-	v.SetMainPanel(textView)
+	v.PushScreen("artist-collaborators", table, screenKeys(openRowKeys, exportKeys, backKeys)...)
 }
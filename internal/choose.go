@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Pick playlists from a checklist of every playlist.
+// Enter toggles a playlist; pressing a key bound to key runs done with the chosen playlists, once at least min are chosen.
+// action describes done, e.g. "find duplicates", and the playlists with the preselected ids start out chosen.
+func choosePlaylists(v *models.View, key models.Action, action string, min int, preselected []string, done func([]models.SimpleIdentifier)) {
+	playlists, err := data.GetPlaylists(v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Playlists: %w", err))
+		return
+	}
+
+	chosen := make([]bool, len(playlists))
+	for i, p := range playlists {
+		for _, id := range preselected {
+			if p.Id == id {
+				chosen[i] = true
+			}
+		}
+	}
+
+	table := newTable(v).SetBorders(false)
+	table.SetBorder(true)
+
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("").SetSelectable(false))
+	table.SetCell(0, 1, tview.NewTableCell("Playlist").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1).SetSelectable(false))
+
+	updateTitle := func() {
+		count := 0
+		for _, c := range chosen {
+			if c {
+				count++
+			}
+		}
+
+		table.SetTitle(fmt.Sprintf("%d of %d playlists chosen (Enter to choose, %s to %s)", count, len(playlists), v.Keymap.Label(key), action))
+	}
+
+	checkbox := func(checked bool) string {
+		if checked {
+			return padLeft("[x]")
+		}
+
+		return padLeft("[ ]")
+	}
+
+	// set table contents
+	for i, p := range playlists {
+		// use i+1 to offset for header row
+		table.SetCell(i+1, 0, tview.NewTableCell(checkbox(chosen[i])).SetTextColor(v.Theme.Cell))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(p.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+	}
+
+	updateTitle()
+
+	// toggle the playlist when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			chosen[row-1] = !chosen[row-1]
+			table.GetCell(row, 0).SetText(checkbox(chosen[row-1]))
+			updateTitle()
+		}
+	})
+
+	table.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case e.Key() == tcell.KeyESC:
+			v.PopScreen()
+			return nil
+		case v.Keymap.Matches(key, e):
+			var picked []models.SimpleIdentifier
+
+			for i, p := range playlists {
+				if chosen[i] {
+					picked = append(picked, p)
+				}
+			}
+
+			if len(picked) < min {
+				noun := "playlists"
+				if min == 1 {
+					noun = "playlist"
+				}
+
+				v.UpdateMessageBar(fmt.Sprintf("Choose at least %d %s to %s", min, noun, action))
+				return nil
+			}
+
+			done(picked)
+
+			return nil
+		}
+
+		return e
+	})
+
+	v.PushScreen("choose-playlists", table, []models.ScreenAction{
+		{Keys: "Enter", Description: "Choose the selected playlist, or unchoose it"},
+		{Action: key, Description: fmt.Sprintf("%s%s with the chosen playlists", strings.ToUpper(action[:1]), action[1:])},
+		{Keys: "Esc", Description: "Go back to the previous screen"},
+	}...)
+}
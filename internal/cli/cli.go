@@ -0,0 +1,562 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/format"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Exit codes, following grep's convention
+const (
+	// the command succeeded and found results
+	ExitOK = 0
+	// the command succeeded but found nothing
+	ExitNoResults = 1
+	// the command was invalid or failed
+	ExitError = 2
+)
+
+// Descriptions of the flags that set the Options, for main and each command's flags
+const (
+	FormatUsage = "output format for commands: json, csv, tsv, table, markdown, m3u or xspf"
+	GroupUsage  = "playlist group for the starred and duplicates commands (default the first group)"
+)
+
+// Options that apply to every command
+type Options struct {
+	Format format.Format
+	// the configured playlist groups
+	Groups []models.PlaylistGroup
+	// name of the group that starred and duplicates run against; the first group if blank
+	Group string
+}
+
+// Get the playlist group named by the options
+func (o Options) group() (models.PlaylistGroup, error) {
+	if len(o.Groups) == 0 {
+		return models.DefaultPlaylistGroup, nil
+	}
+
+	if o.Group == "" {
+		return o.Groups[0], nil
+	}
+
+	var names []string
+	for _, g := range o.Groups {
+		if strings.EqualFold(g.Name, o.Group) {
+			return g, nil
+		}
+
+		names = append(names, g.Name)
+	}
+
+	return models.PlaylistGroup{}, fmt.Errorf("unknown playlist group '%s', expected one of: %s", o.Group, strings.Join(names, ", "))
+}
+
+// Records printed by a command
+type result struct {
+	columns []string
+	records []models.Record
+	// printed to errOut after the records, so that it doesn't mix with them
+	summary string
+	// the records as tracks, for writing as a playlist file; nil if the records aren't tracks
+	entries []models.PlaylistEntry
+	// title of the playlist file
+	title string
+}
+
+type command struct {
+	name        string
+	args        string
+	description string
+	// returns a nil result if the command has no output
+	run func(args []string, opts Options, repo *db.Repository) (*result, error)
+}
+
+var commands = []command{
+	{"search", "artists|albums|songs|playlists|everything <query>", fmt.Sprintf("Search for entities whose name matches the query; everything lists at most %d of each type", data.SearchEverythingLimit), runSearch},
+	{"starred", "<query>", "Search the -group playlists for tracks, albums and artists matching the query", runStarred},
+	{"duplicates", "[all | <playlist id>...]", "List songs repeated across the -group playlists, every playlist or the listed playlists", runDuplicates},
+	{"similar", "[all | <playlist id>...]", "List groups of similar songs, e.g. a single and its remaster, across the same playlists as duplicates", runSimilar},
+	{"groups", "", "List the playlists in each playlist group", runGroups},
+	{"compare", "<playlist id> <playlist id>...", "List the tracks the playlists have in common and the tracks unique to each", runCompare},
+	{"artist-albums", "<artist id>", "List the artist's albums", runArtistAlbums},
+	{"artist-tracks", "<artist id>", "List the artist's tracks and the playlists containing them", runArtistTracks},
+	{"artist-playlists", "<artist id>", "List playlists containing tracks by the artist", runArtistPlaylists},
+	{"artist-collaborators", "<artist id>", "List artists credited on the same tracks as the artist, by number of shared tracks", runArtistCollaborators},
+	{"album", "<album id>", "List the album's tracks and the playlists containing them", runAlbum},
+	{"song", "<track id>", "List the playlists containing the song", runSong},
+	{"playlist", "<playlist id>", "List the playlist's tracks", runPlaylist},
+	{"stats", "", "Show counts of playlists, tracks, albums and artists, the top artists and albums, tracks added per month and album types", runStats},
+	{"index", "", "Build or refresh the full-text search index", runIndex},
+}
+
+// Print the list of subcommands
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, c.args, c.description)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nFlags can be given before or after the command; use -- to pass arguments that start with '-'.")
+	fmt.Fprintln(w, "Results are printed as a table unless -format is json, csv, tsv or markdown.")
+	fmt.Fprintln(w, "Lists of tracks can also be written as m3u or xspf playlist files.")
+	fmt.Fprintln(w, "Run without a command to start the interactive UI.")
+	fmt.Fprintf(w, "Exit status is %d if there are results, %d if there are none and %d on error.\n", ExitOK, ExitNoResults, ExitError)
+}
+
+// Run the subcommand named by the first argument, printing its results to out in the format.
+// Returns the process exit code.
+func Run(args []string, opts Options, repo *db.Repository, out io.Writer, errOut io.Writer) int {
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+
+		opts, commandArgs, err := parseCommandFlags(c, args[1:], opts, errOut)
+
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		if err != nil {
+			return ExitError
+		}
+
+		r, err := c.run(commandArgs, opts, repo)
+
+		if err != nil {
+			fmt.Fprintf(errOut, "%s: %v\n", c.name, err)
+			return ExitError
+		}
+
+		if r == nil {
+			return ExitOK
+		}
+
+		if opts.Format.IsPlaylist() {
+			return writePlaylist(c.name, opts.Format, r, out, errOut)
+		}
+
+		if err := format.Write(out, opts.Format, r.columns, r.records); err != nil {
+			fmt.Fprintf(errOut, "%s: %v\n", c.name, err)
+			return ExitError
+		}
+
+		if r.summary != "" {
+			fmt.Fprintln(errOut, r.summary)
+		}
+
+		if len(r.records) == 0 {
+			return ExitNoResults
+		}
+
+		return ExitOK
+	}
+
+	fmt.Fprintf(errOut, "unknown command '%s'\n\n", args[0])
+	Usage(errOut)
+
+	return ExitError
+}
+
+// Parse the -format and -group flags anywhere in the command's args, defaulting to the values given before the command,
+// e.g. "search artists radiohead -format json". Returns the options and the args that aren't flags.
+// Errors are printed to errOut along with the command's usage.
+func parseCommandFlags(c command, args []string, opts Options, errOut io.Writer) (Options, []string, error) {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprintf(errOut, "Usage: %s %s [flags]\n\n%s\n\nFlags:\n", c.name, c.args, c.description)
+		fs.PrintDefaults()
+	}
+
+	formatName := fs.String("format", string(opts.Format), FormatUsage)
+	group := fs.String("group", opts.Group, GroupUsage)
+
+	var positional []string
+
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return opts, nil, err
+		}
+
+		rest := fs.Args()
+
+		// Parse stops at "--" after consuming it, and every arg after it is positional
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+
+		// Parse also stops at the first positional arg, so keep it and parse the args after it
+		if len(rest) > 0 {
+			positional = append(positional, rest[0])
+			rest = rest[1:]
+		}
+
+		args = rest
+	}
+
+	outputFormat, err := format.Parse(*formatName)
+
+	if err != nil {
+		fmt.Fprintf(errOut, "%s: %v\n", c.name, err)
+		return opts, nil, err
+	}
+
+	opts.Format, opts.Group = outputFormat, *group
+
+	return opts, positional, nil
+}
+
+// Write the command's tracks as a playlist file, returning the process exit code
+func writePlaylist(name string, f format.Format, r *result, out io.Writer, errOut io.Writer) int {
+	if r.entries == nil {
+		fmt.Fprintf(errOut, "%s: -format %s is only supported by commands that list tracks\n", name, f)
+		return ExitError
+	}
+
+	if err := format.WritePlaylist(out, f, r.title, r.entries); err != nil {
+		fmt.Fprintf(errOut, "%s: %v\n", name, err)
+		return ExitError
+	}
+
+	if len(r.entries) == 0 {
+		return ExitNoResults
+	}
+
+	return ExitOK
+}
+
+// Join the args into a single query, so that multi-word queries don't have to be quoted
+func queryArg(args []string) (string, error) {
+	query := strings.TrimSpace(strings.Join(args, " "))
+
+	if query == "" {
+		return "", fmt.Errorf("missing query")
+	}
+
+	return query, nil
+}
+
+func idArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected exactly 1 id, got %d arguments", len(args))
+	}
+
+	return args[0], nil
+}
+
+func runSearch(args []string, opts Options, repo *db.Repository) (*result, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing entity type")
+	}
+
+	query, err := queryArg(args[1:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "artists":
+		artists, err := data.SearchArtists(ctx, query, repo)
+		return &result{columns: models.SimpleIdentifier{}.Columns(), records: models.SimpleIdentifierRecords(artists)}, err
+	case "albums":
+		albums, err := data.SearchAlbums(ctx, query, repo)
+		return &result{columns: models.Album{}.Columns(), records: models.AlbumRecords(albums)}, err
+	case "songs":
+		tracks, err := data.SearchTracks(ctx, query, repo)
+		return &result{columns: models.Track{}.Columns(), records: models.TrackRecords(tracks), entries: models.TrackEntries(tracks), title: fmt.Sprintf("Songs matching '%s'", query)}, err
+	case "playlists":
+		playlists, err := data.SearchPlaylists(ctx, query, repo)
+		return &result{columns: models.SimpleIdentifier{}.Columns(), records: models.SimpleIdentifierRecords(playlists)}, err
+	case "everything":
+		results, err := data.SearchEverything(ctx, query, data.SearchEverythingLimit, repo)
+		return &result{columns: models.SearchResult{}.Columns(), records: models.SearchResultRecords(results.Flatten()), summary: truncatedSummary(results)}, err
+	}
+
+	return nil, fmt.Errorf("unknown entity type '%s', expected artists, albums, songs, playlists or everything", args[0])
+}
+
+// Say which entity types of an everything search had more matches than were printed, and how to list them all
+func truncatedSummary(results models.SearchResults) string {
+	var lines []string
+
+	for _, t := range []struct {
+		entityType string
+		printed    int
+		count      int
+	}{
+		{"artists", len(results.Artists), results.ArtistCount},
+		{"albums", len(results.Albums), results.AlbumCount},
+		{"songs", len(results.Tracks), results.TrackCount},
+		{"playlists", len(results.Playlists), results.PlaylistCount},
+	} {
+		if t.count > t.printed {
+			lines = append(lines, fmt.Sprintf("showing %d of %d %s; use 'search %s' to list them all", t.printed, t.count, t.entityType, t.entityType))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func runStarred(args []string, opts Options, repo *db.Repository) (*result, error) {
+	query, err := queryArg(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := opts.group()
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := data.SearchPlaylistGroup(context.Background(), group, query, repo)
+
+	return &result{columns: models.StarredPlaylistMatch{}.Columns(), records: models.StarredPlaylistMatchRecords(matches), entries: models.StarredPlaylistMatchEntries(matches), title: fmt.Sprintf("%s Playlist matches for '%s'", group.Name, query)}, err
+}
+
+func runDuplicates(args []string, opts Options, repo *db.Repository) (*result, error) {
+	playlists, err := playlistsArg(args, opts, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dupes, err := data.FindDuplicateTracks(playlists, repo)
+
+	return &result{columns: models.DuplicateTrack{}.Columns(), records: models.DuplicateTrackRecords(dupes)}, err
+}
+
+func runSimilar(args []string, opts Options, repo *db.Repository) (*result, error) {
+	playlists, err := playlistsArg(args, opts, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := data.FindSimilarTracks(playlists, data.SimilarDurationTolerance, repo)
+
+	return &result{columns: models.SimilarTracksColumns(), records: models.SimilarTracksRecords(groups)}, err
+}
+
+// Get the playlists named by the args:
+// "all" for every playlist, a list of playlist ids, or the -group playlists if there are no args
+func playlistsArg(args []string, opts Options, repo *db.Repository) (data.PlaylistSet, error) {
+	switch {
+	case len(args) == 1 && args[0] == "all":
+		return data.AllPlaylists, nil
+	case len(args) > 1 && args[0] == "all":
+		return data.PlaylistSet{}, fmt.Errorf("unexpected arguments after 'all' %v", args[1:])
+	case len(args) > 0:
+		return data.PlaylistSet{Ids: args}, nil
+	}
+
+	group, err := opts.group()
+
+	if err != nil {
+		return data.PlaylistSet{}, err
+	}
+
+	playlists, err := data.GetPlaylistGroupPlaylists(group, repo)
+
+	return data.PlaylistsOf(playlists), err
+}
+
+// Get the artist whose id is the only arg
+func artistArg(args []string, repo *db.Repository) (models.SimpleIdentifier, error) {
+	id, err := idArg(args)
+
+	if err != nil {
+		return models.SimpleIdentifier{}, err
+	}
+
+	artist, err := data.GetArtist(id, repo)
+
+	return artist, notFound("artist", id, err)
+}
+
+// Report a missing row as the entity not being found, rather than as an empty result
+func notFound(entity string, id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s '%s' not found", entity, id)
+	}
+
+	return err
+}
+
+func runArtistAlbums(args []string, opts Options, repo *db.Repository) (*result, error) {
+	artist, err := artistArg(args, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	albums, err := data.GetAlbumsByArtist(&artist, repo)
+
+	return &result{columns: models.Album{}.Columns(), records: models.AlbumRecords(albums)}, err
+}
+
+func runArtistTracks(args []string, opts Options, repo *db.Repository) (*result, error) {
+	artist, err := artistArg(args, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, err := data.GetTracksByArtist(&artist, repo)
+
+	return &result{columns: models.Track{}.Columns(), records: models.TrackRecords(tracks), entries: models.TrackEntries(tracks)}, err
+}
+
+func runArtistPlaylists(args []string, opts Options, repo *db.Repository) (*result, error) {
+	artist, err := artistArg(args, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	playlists, err := data.FindPlaylistsContainingArtist(artist, repo)
+
+	return &result{columns: models.SimpleIdentifier{}.Columns(), records: models.SimpleIdentifierRecords(playlists)}, err
+}
+
+func runArtistCollaborators(args []string, opts Options, repo *db.Repository) (*result, error) {
+	artist, err := artistArg(args, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators, err := data.GetCollaborators(artist, repo)
+
+	return &result{columns: models.Collaborator{}.Columns(), records: models.CollaboratorRecords(collaborators)}, err
+}
+
+func runAlbum(args []string, opts Options, repo *db.Repository) (*result, error) {
+	id, err := idArg(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := data.GetAlbum(id, repo); err != nil {
+		return nil, notFound("album", id, err)
+	}
+
+	tracks, err := data.GetAlbumTracks(id, repo)
+
+	return &result{columns: models.Track{}.Columns(), records: models.TrackRecords(tracks)}, err
+}
+
+func runSong(args []string, opts Options, repo *db.Repository) (*result, error) {
+	id, err := idArg(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := data.GetTrack(id, repo); err != nil {
+		return nil, notFound("song", id, err)
+	}
+
+	appearances, err := data.GetPlaylistsContainingTrack(id, repo)
+
+	return &result{columns: models.PlaylistAppearance{}.Columns(), records: models.PlaylistAppearanceRecords(appearances)}, err
+}
+
+func runPlaylist(args []string, opts Options, repo *db.Repository) (*result, error) {
+	id, err := idArg(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	playlist, err := data.GetPlaylist(id, repo)
+
+	if err != nil {
+		return nil, notFound("playlist", id, err)
+	}
+
+	tracks, err := data.GetPlaylistTracks(id, repo)
+
+	return &result{columns: models.Track{}.Columns(), records: models.TrackRecords(tracks), entries: models.TrackEntries(tracks), title: playlist.Name}, err
+}
+
+func runCompare(args []string, opts Options, repo *db.Repository) (*result, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("expected at least 2 playlist ids, got %d", len(args))
+	}
+
+	comparison, err := data.ComparePlaylists(args, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{columns: models.PlaylistComparisonColumns(), records: comparison.Records(), summary: comparison.Summary()}, nil
+}
+
+func runGroups(args []string, opts Options, repo *db.Repository) (*result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", args)
+	}
+
+	groups := opts.Groups
+	if len(groups) == 0 {
+		groups = []models.PlaylistGroup{models.DefaultPlaylistGroup}
+	}
+
+	var records []models.Record
+
+	for _, g := range groups {
+		playlists, err := data.GetPlaylistGroupPlaylists(g, repo)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range playlists {
+			records = append(records, models.PlaylistGroupMember{Group: g.Name, PlaylistId: p.Id, PlaylistName: p.Name})
+		}
+	}
+
+	return &result{columns: models.PlaylistGroupMember{}.Columns(), records: records}, nil
+}
+
+func runStats(args []string, opts Options, repo *db.Repository) (*result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", args)
+	}
+
+	stats, err := data.GetLibraryStats(data.StatsLimit, repo)
+
+	return &result{columns: models.LibraryStatsColumns(), records: stats.Records()}, err
+}
+
+func runIndex(args []string, opts Options, repo *db.Repository) (*result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", args)
+	}
+
+	return nil, data.BuildSearchIndex(repo)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/format"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Schema and rows of a small library for the commands to query
+var testDbStatements = []string{
+	"CREATE TABLE Artist(id TEXT PRIMARY KEY, name TEXT NOT NULL)",
+	"CREATE TABLE Album(id TEXT PRIMARY KEY, name TEXT NOT NULL, total_tracks INT NOT NULL, release_date TEXT NOT NULL, album_type TEXT NOT NULL)",
+	"CREATE TABLE AlbumArtist(album_id TEXT, artist_id TEXT)",
+	"CREATE TABLE Track(id TEXT PRIMARY KEY, name TEXT NOT NULL, track_number INT NOT NULL, disc_number INT NOT NULL DEFAULT 1, duration_ms INT NOT NULL, album_id TEXT NOT NULL)",
+	"CREATE TABLE TrackArtist(track_id TEXT, artist_id TEXT)",
+	"CREATE TABLE Playlist(id TEXT PRIMARY KEY, name TEXT NOT NULL, snapshot_id TEXT, description TEXT)",
+	"CREATE TABLE PlaylistTrack(playlist_id TEXT, track_id TEXT, added_at TEXT)",
+	"INSERT INTO Artist VALUES ('ar1', 'Radiohead'), ('ar2', 'Thom Yorke'), ('ar3', 'Portishead')",
+	"INSERT INTO Album VALUES ('al1', 'OK Computer', 2, '1997-05-21', 'album')",
+	"INSERT INTO AlbumArtist VALUES ('al1', 'ar1')",
+	"INSERT INTO Track VALUES ('t1', 'Airbag', 1, 1, 284000, 'al1'), ('t2', 'Paranoid Android', 2, 1, 383000, 'al1')",
+	"INSERT INTO TrackArtist VALUES ('t1', 'ar1'), ('t2', 'ar1'), ('t2', 'ar2')",
+	"INSERT INTO Playlist VALUES ('p1', 'Starred 2020', 's1', ''), ('p2', 'Empty', 's2', '')",
+	"INSERT INTO PlaylistTrack VALUES ('p1', 't1', '2020-01-01T00:00:00Z')",
+}
+
+func openTestRepository(t *testing.T) *db.Repository {
+	t.Helper()
+
+	repo, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+
+	if err != nil {
+		t.Fatalf("could not open the DB: %v", err)
+	}
+
+	t.Cleanup(func() { repo.Close() })
+
+	tx, err := repo.Begin()
+
+	if err != nil {
+		t.Fatalf("could not start a transaction: %v", err)
+	}
+
+	for _, statement := range testDbStatements {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			t.Fatalf("could not run %q: %v", statement, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("could not commit: %v", err)
+	}
+
+	return repo
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+		// a substring of what's printed to errOut
+		wantErr string
+	}{
+		{args: []string{"search", "artists", "radio"}, want: ExitOK},
+		{args: []string{"search", "artists", "nope"}, want: ExitNoResults},
+		{args: []string{"search", "artists"}, want: ExitError, wantErr: "missing query"},
+		{args: []string{"search", "everything", "air"}, want: ExitOK},
+		{args: []string{"search", "everything", "nope"}, want: ExitNoResults},
+		{args: []string{"search", "nope", "radio"}, want: ExitError, wantErr: "unknown entity type 'nope'"},
+		{args: []string{"artist-albums", "ar1"}, want: ExitOK},
+		{args: []string{"artist-albums", "ar3"}, want: ExitNoResults},
+		{args: []string{"artist-albums", "nope"}, want: ExitError, wantErr: "artist 'nope' not found"},
+		{args: []string{"artist-tracks", "ar2"}, want: ExitOK},
+		{args: []string{"artist-tracks", "nope"}, want: ExitError, wantErr: "artist 'nope' not found"},
+		{args: []string{"artist-playlists", "ar1"}, want: ExitOK},
+		{args: []string{"artist-playlists", "ar3"}, want: ExitNoResults},
+		{args: []string{"artist-playlists", "nope"}, want: ExitError, wantErr: "artist 'nope' not found"},
+		{args: []string{"artist-collaborators", "ar2"}, want: ExitOK},
+		{args: []string{"artist-collaborators", "nope"}, want: ExitError, wantErr: "artist 'nope' not found"},
+		{args: []string{"album", "al1"}, want: ExitOK},
+		{args: []string{"album", "nope"}, want: ExitError, wantErr: "album 'nope' not found"},
+		{args: []string{"song", "t1"}, want: ExitOK},
+		{args: []string{"song", "t2"}, want: ExitNoResults},
+		{args: []string{"song", "nope"}, want: ExitError, wantErr: "song 'nope' not found"},
+		{args: []string{"playlist", "p1"}, want: ExitOK},
+		{args: []string{"playlist", "p2"}, want: ExitNoResults},
+		{args: []string{"playlist", "nope"}, want: ExitError, wantErr: "playlist 'nope' not found"},
+		{args: []string{"playlist"}, want: ExitError, wantErr: "expected exactly 1 id, got 0 arguments"},
+		{args: []string{"playlist", "p1", "p2"}, want: ExitError, wantErr: "expected exactly 1 id, got 2 arguments"},
+		{args: []string{"nope"}, want: ExitError, wantErr: "unknown command 'nope'"},
+	}
+
+	repo := openTestRepository(t)
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			if got := Run(tt.args, Options{Format: format.TSV}, repo, &out, &errOut); got != tt.want {
+				t.Errorf("Run() = %d, want %d; errOut: %s", got, tt.want, errOut.String())
+			}
+
+			if !strings.Contains(errOut.String(), tt.wantErr) {
+				t.Errorf("errOut = %q, want it to contain %q", errOut.String(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTruncatedSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		results models.SearchResults
+		want    string
+	}{
+		{"nothing found", models.SearchResults{}, ""},
+		{
+			"everything printed",
+			models.SearchResults{Artists: []models.SimpleIdentifier{{Id: "ar1"}}, ArtistCount: 1},
+			"",
+		},
+		{
+			"some types truncated",
+			models.SearchResults{
+				Artists:       []models.SimpleIdentifier{{Id: "ar1"}},
+				ArtistCount:   3,
+				Tracks:        []models.Track{{Id: "t1"}},
+				TrackCount:    1,
+				Playlists:     []models.SimpleIdentifier{{Id: "p1"}, {Id: "p2"}},
+				PlaylistCount: 40,
+			},
+			"showing 1 of 3 artists; use 'search artists' to list them all\nshowing 2 of 40 playlists; use 'search playlists' to list them all",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncatedSummary(tt.results); got != tt.want {
+				t.Errorf("truncatedSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Pick playlists to compare with the playlist, then compare them
+func choosePlaylistsToCompare(v *models.View, playlist models.SimpleIdentifier) {
+	v.UpdateTitleBar(fmt.Sprintf("Choose Playlists to compare with %s", playlist.Name))
+
+	choosePlaylists(v, models.ActionPlaylistCompare, "compare", 2, []string{playlist.Id}, func(picked []models.SimpleIdentifier) {
+		ids := make([]string, len(picked))
+		for i, p := range picked {
+			ids[i] = p.Id
+		}
+
+		ComparePlaylists(v, ids)
+	})
+}
+
+// Display the tracks the playlists have in common and the tracks unique to each
+func ComparePlaylists(v *models.View, playlistIds []string) {
+	comparison, err := data.ComparePlaylists(playlistIds, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not compare Playlists: %w", err))
+		return
+	}
+
+	v.UpdateTitleBar(fmt.Sprintf("Comparing %d Playlists", len(comparison.Playlists)))
+
+	displayPlaylistComparison(v, comparison)
+}
+
+func displayPlaylistComparison(v *models.View, comparison models.PlaylistComparison) {
+	header := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	header.SetText(fmt.Sprintf("%s\n%d of %d tracks are in every playlist", colorText(v.Theme.Header, "b", fmt.Sprintf("%.0f%% overlap", comparison.Overlap*100)), len(comparison.Common), comparison.TotalTracks()))
+
+	table := newTable(v).SetBorders(false)
+	table.SetBorder(true)
+
+	// the track displayed in each row; nil for the section headers
+	var tracks []*models.Track
+
+	addSection := func(name string, section []models.Track) {
+		row := table.GetRowCount()
+		table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf("%s (%d)", name, len(section))).SetTextColor(v.Theme.Header).SetAttributes(tcell.AttrBold).SetSelectable(false))
+		table.SetCell(row, 1, tview.NewTableCell("").SetSelectable(false))
+		table.SetCell(row, 2, tview.NewTableCell("").SetSelectable(false))
+		table.SetCell(row, 3, tview.NewTableCell("").SetSelectable(false))
+		tracks = append(tracks, nil)
+
+		for i := range section {
+			track := &section[i]
+
+			row++
+			table.SetCell(row, 0, tview.NewTableCell(padLeft(track.Name)).SetTextColor(v.Theme.Cell).SetExpansion(2))
+			table.SetCell(row, 1, tview.NewTableCell(padLeft(track.Artists)).SetTextColor(v.Theme.Cell).SetExpansion(2))
+			table.SetCell(row, 2, tview.NewTableCell(padLeft(track.AlbumName)).SetTextColor(v.Theme.Cell).SetExpansion(2))
+			table.SetCell(row, 3, tview.NewTableCell(padLeft(track.Playlists)).SetTextColor(v.Theme.Muted).SetExpansion(2))
+			tracks = append(tracks, track)
+		}
+	}
+
+	addSection(fmt.Sprintf("In all %d playlists", len(comparison.Playlists)), comparison.Common)
+
+	if len(comparison.Playlists) > 2 {
+		addSection("In some playlists", comparison.Shared)
+	}
+
+	for i, p := range comparison.Playlists {
+		addSection(fmt.Sprintf("Only in %s", p.Name), comparison.Unique[i])
+	}
+
+	// start on the first track, skipping the section headers
+	first := 0
+	for first < len(tracks)-1 && tracks[first] == nil {
+		first++
+	}
+
+	// open the Song when its row is selected
+	table.SetSelectable(true, false).Select(first, 0).SetSelectedFunc(func(row int, column int) {
+		if track := tracks[row]; track != nil {
+			SelectSong(v, track.Id, track.Name)
+		}
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 2, 0, false).
+		AddItem(table, 0, 1, true)
+
+	// the Export action exports the comparison, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{name: "comparison", columns: models.PlaylistComparisonColumns(), records: comparison.Records()}
+	}, BackFunc(v)))
+
+	v.PushScreen("playlist-comparison", flex, screenKeys(openRowKeys, exportKeys, backKeys)...)
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"database/sql"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Find the artists credited on the same tracks as the artist, with the most shared tracks first
+func GetCollaborators(artist models.SimpleIdentifier, repo *db.Repository) ([]models.Collaborator, error) {
+	// the DISTINCT subquery counts each shared track id once, even if the DB has duplicate TrackArtist rows for it
+	/*
+		SELECT AR.id, AR.name, COUNT(*) AS shared, GROUP_CONCAT(S.track_name, '; ')
+		FROM (SELECT DISTINCT TA.artist_id, T.id AS track_id, T.name AS track_name
+		      FROM TrackArtist A
+		               JOIN TrackArtist TA ON A.track_id = TA.track_id AND TA.artist_id != A.artist_id
+		               JOIN Track T ON TA.track_id = T.id
+		      WHERE A.artist_id = @Id
+		      ORDER BY T.name) S
+		         JOIN Artist AR ON S.artist_id = AR.id
+		GROUP BY AR.id
+		ORDER BY shared DESC, AR.name
+	*/
+	rows, err := repo.Query(
+		"SELECT AR.id, AR.name, COUNT(*) AS shared, GROUP_CONCAT(S.track_name, '; ') FROM (SELECT DISTINCT TA.artist_id, T.id AS track_id, T.name AS track_name FROM TrackArtist A JOIN TrackArtist TA ON A.track_id = TA.track_id AND TA.artist_id != A.artist_id JOIN Track T ON TA.track_id = T.id WHERE A.artist_id = @Id ORDER BY T.name) S JOIN Artist AR ON S.artist_id = AR.id GROUP BY AR.id ORDER BY shared DESC, AR.name",
+		sql.Named("Id", artist.Id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var collaborators []models.Collaborator
+
+	for rows.Next() {
+		var c models.Collaborator
+
+		if err := rows.Scan(&c.Artist.Id, &c.Artist.Name, &c.SharedTracks, &c.Tracks); err != nil {
+			return nil, err
+		}
+
+		collaborators = append(collaborators, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collaborators, nil
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Compare the tracks of two or more playlists.
+// Each track's Playlists lists the compared playlists that contain it.
+func ComparePlaylists(playlistIds []string, repo *db.Repository) (models.PlaylistComparison, error) {
+	comparison := models.PlaylistComparison{}
+
+	// index of each playlist in the comparison
+	indexes := make(map[string]int)
+
+	for _, id := range playlistIds {
+		if _, ok := indexes[id]; ok {
+			continue
+		}
+
+		playlist, err := GetPlaylist(id, repo)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return comparison, fmt.Errorf("there is no playlist with id '%s'", id)
+		}
+
+		if err != nil {
+			return comparison, fmt.Errorf("could not load playlist '%s': %w", id, err)
+		}
+
+		indexes[id] = len(comparison.Playlists)
+		comparison.Playlists = append(comparison.Playlists, playlist)
+	}
+
+	if len(comparison.Playlists) < 2 {
+		return comparison, fmt.Errorf("expected at least 2 different playlists to compare, got %d", len(comparison.Playlists))
+	}
+
+	comparison.Unique = make([][]models.Track, len(comparison.Playlists))
+
+	/*
+		SELECT T.id,
+		       T.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       A.id,
+		       A.name,
+		       T.duration_ms,
+		       GROUP_CONCAT(DISTINCT PT.playlist_id) AS playlistIds
+		FROM PlaylistTrack PT
+		         JOIN Track T ON PT.track_id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		WHERE PT.playlist_id IN (@PlaylistIds)
+		GROUP BY T.id
+		ORDER BY T.name, A.name
+	*/
+	query, args := playlistIdsQuery(
+		"SELECT T.id, T.name, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, A.id, A.name, T.duration_ms, GROUP_CONCAT(DISTINCT PT.playlist_id) AS playlistIds FROM PlaylistTrack PT JOIN Track T ON PT.track_id = T.id JOIN Album A ON T.album_id = A.id WHERE PT.playlist_id IN (@PlaylistIds) GROUP BY T.id ORDER BY T.name, A.name",
+		PlaylistSet{Ids: playlistIds})
+
+	rows, err := queryPlaylistSet(context.Background(), PlaylistSet{Ids: playlistIds}, repo, query, args...)
+
+	if err != nil {
+		return comparison, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var track models.Track
+		var ids string
+
+		if err := rows.Scan(&track.Id, &track.Name, &track.Artists, &track.AlbumId, &track.AlbumName, &track.DurationMs, &ids); err != nil {
+			return comparison, err
+		}
+
+		// name the playlists in the order they're being compared
+		in := make([]bool, len(comparison.Playlists))
+		for _, id := range strings.Split(ids, ",") {
+			in[indexes[id]] = true
+		}
+
+		var names []string
+		for i, p := range comparison.Playlists {
+			if in[i] {
+				names = append(names, p.Name)
+			}
+		}
+
+		track.Playlists = strings.Join(names, "; ")
+
+		switch len(names) {
+		case len(comparison.Playlists):
+			comparison.Common = append(comparison.Common, track)
+		case 1:
+			for i := range in {
+				if in[i] {
+					comparison.Unique[i] = append(comparison.Unique[i], track)
+				}
+			}
+		default:
+			comparison.Shared = append(comparison.Shared, track)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return comparison, err
+	}
+
+	if total := comparison.TotalTracks(); total > 0 {
+		comparison.Overlap = float64(len(comparison.Common)) / float64(total)
+	}
+
+	return comparison, nil
+}
@@ -1,15 +1,26 @@
 package data
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"sort"
+	"strings"
 
+	"github.com/ccb012100/go-playlist-search/internal/db"
 	"github.com/ccb012100/go-playlist-search/internal/models"
 )
 
-func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Album {
-	database, _ := sql.Open("sqlite3", db)
+// Maximum number of matches of each entity type in an everything search
+const SearchEverythingLimit = 25
 
+// A LIMIT that returns every row
+const noLimit = -1
+
+// Returned by searches that would match every row for an empty query
+var ErrEmptyQuery = errors.New("the search query is empty")
+
+func GetAlbumsByArtist(artist *models.SimpleIdentifier, repo *db.Repository) ([]models.Album, error) {
 	// Get albums by the artist
 	/*
 		select id, name, total_tracks, release_date, album_type
@@ -17,14 +28,16 @@ func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Albu
 		         join AlbumArtist AA on a.id = AA.album_id
 		where AA.artist_id = @Id
 	*/
-	albumArtistRows, err := database.Query(
+	albumArtistRows, err := repo.Query(
 		"select id, name, total_tracks, release_date, album_type from Album a join AlbumArtist AA on a.id = AA.album_id where AA.artist_id = @Id",
 		sql.Named("Id", artist.Id))
 
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	defer albumArtistRows.Close()
+
 	var albums []models.Album
 	// track albums in a map so that we display a unique set
 	var set = make(map[string]bool)
@@ -34,7 +47,7 @@ func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Albu
 		var totalTracks int
 
 		if err := albumArtistRows.Scan(&id, &name, &totalTracks, &releaseDate, &albumType); err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		// skip if the album is already in the slice
@@ -53,8 +66,12 @@ func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Albu
 		})
 	}
 
+	if err := albumArtistRows.Err(); err != nil {
+		return nil, err
+	}
+
 	// Get albums with Tracks the Artist appears on
-	trackArtistRows, err := database.Query(
+	trackArtistRows, err := repo.Query(
 		/*
 			select A.id, A.name, total_tracks, release_date, album_type
 			from Album A
@@ -66,15 +83,17 @@ func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Albu
 		sql.Named("Id", artist.Id))
 
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	defer trackArtistRows.Close()
+
 	for trackArtistRows.Next() {
 		var id, name, releaseDate, albumType string
 		var totalTracks int
 
 		if err := trackArtistRows.Scan(&id, &name, &totalTracks, &releaseDate, &albumType); err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		// skip if the album is already in the slice
@@ -93,37 +112,67 @@ func GetAlbumsByArtist(artist *models.SimpleIdentifier, db string) []models.Albu
 		})
 	}
 
+	if err := trackArtistRows.Err(); err != nil {
+		return nil, err
+	}
+
 	// sort albums
 	sort.Sort(models.ByName(albums))
 
-	return albums
+	return albums, nil
 }
 
-func SearchArtists(query string, db string) []models.SimpleIdentifier {
-	database, _ := sql.Open("sqlite3", db)
+func SearchArtists(ctx context.Context, query string, repo *db.Repository) ([]models.SimpleIdentifier, error) {
+	artists, _, err := searchArtists(ctx, query, noLimit, repo)
 
-	rows, err := database.Query("SELECT id, name FROM Artist WHERE name LIKE '%' || @Query || '%' ORDER BY name",
-		sql.Named("Query", query))
+	return artists, err
+}
+
+// SearchArtists, returning at most limit matches and the number of matches before the limit
+func searchArtists(ctx context.Context, query string, limit int, repo *db.Repository) ([]models.SimpleIdentifier, int, error) {
+	/*
+		SELECT A.id, A.name, COUNT(*) OVER () AS total
+		FROM ArtistSearch S
+		         JOIN Artist A ON S.id = A.id
+		WHERE ArtistSearch MATCH @Match
+		ORDER BY S.rank, A.name
+		LIMIT @Limit
+	*/
+	rows, err := searchQuery(ctx, query, repo,
+		"SELECT A.id, A.name, COUNT(*) OVER () AS total FROM ArtistSearch S JOIN Artist A ON S.id = A.id WHERE ArtistSearch MATCH @Match ORDER BY S.rank, A.name LIMIT @Limit",
+		"SELECT id, name, COUNT(*) OVER () AS total FROM Artist WHERE name LIKE '%' || @Query || '%' ORDER BY name LIMIT @Limit",
+		sql.Named("Limit", limit))
 
 	if err != nil {
-		panic(err)
+		return nil, 0, err
 	}
 
+	defer rows.Close()
+
 	var artists []models.SimpleIdentifier
 
+	// number of matches before the limit, which every row repeats
+	total := 0
+
 	for rows.Next() {
 		var id string
 		var name string
 
-		rows.Scan(&id, &name)
+		if err := rows.Scan(&id, &name, &total); err != nil {
+			return nil, 0, err
+		}
+
 		artists = append(artists, models.SimpleIdentifier{Id: id, Name: name})
 	}
 
-	return artists
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return artists, total, nil
 }
 
-func FindPlaylistsContainingArtist(artist models.SimpleIdentifier, db string) []models.SimpleIdentifier {
-	database, _ := sql.Open("sqlite3", db)
+func FindPlaylistsContainingArtist(artist models.SimpleIdentifier, repo *db.Repository) ([]models.SimpleIdentifier, error) {
 	/*
 		select PL.id, PL.name
 		from Playlist PL
@@ -134,21 +183,23 @@ func FindPlaylistsContainingArtist(artist models.SimpleIdentifier, db string) []
 		group by PL.id, PL.name
 		order by Pl.name
 	*/
-	sqlRows, err := database.Query(
+	sqlRows, err := repo.Query(
 		"select PL.id, PL.name from Playlist PL join PlaylistTrack PT on PL.id = PT.playlist_id join Track T on PT.track_id = T.id join TrackArtist TA on T.id = TA.track_id where TA.artist_id = @Id group by PL.id, PL.name order by Pl.name",
 		sql.Named("Id", artist.Id))
 
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	defer sqlRows.Close()
+
 	var playlists []models.SimpleIdentifier
 
 	for sqlRows.Next() {
 		var id, name string
 
 		if err := sqlRows.Scan(&id, &name); err != nil {
-			panic(err)
+			return nil, err
 		}
 
 		playlists = append(playlists, models.SimpleIdentifier{
@@ -157,130 +208,614 @@ func FindPlaylistsContainingArtist(artist models.SimpleIdentifier, db string) []
 		})
 	}
 
-	return playlists
+	if err := sqlRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return playlists, nil
 }
 
-func SearchStarredPlaylists(query string, db string) []models.StarredPlaylistMatch {
-	database, _ := sql.Open("sqlite3", db)
+func SearchPlaylistGroup(ctx context.Context, group models.PlaylistGroup, query string, repo *db.Repository) ([]models.StarredPlaylistMatch, error) {
+	ids, err := playlistGroupIds(group, repo)
 
-	// Get albums by the artist
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	playlists := PlaylistSet{Ids: ids}
+
+	// Get tracks in the group's playlists whose name, album or artists match the query, best matches first
 	/*
-		SELECT P.name AS playlistName, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists
+		WITH Matches AS (SELECT id, rank FROM TrackSearch WHERE TrackSearch MATCH @Match)
+		SELECT P.name AS playlistName, T.id AS trackId, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists, T.duration_ms AS durationMs
+		FROM Matches M
+		         JOIN PlaylistTrack PT ON M.id = PT.track_id
+		         JOIN Playlist P ON PT.playlist_id = P.id
+		         JOIN Track T ON M.id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		         JOIN TrackArtist TA ON T.id = TA.track_id
+		         JOIN Artist A2 ON TA.artist_id = A2.id
+		WHERE P.id IN (@PlaylistIds)
+		GROUP BY P.name, T.id, A.id, PT.added_at, T.track_number
+		ORDER BY MIN(M.rank), P.name, PT.added_at, T.track_number
+	*/
+	// fallback when the search index hasn't been built
+	/*
+		SELECT P.name AS playlistName, T.id AS trackId, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists, T.duration_ms AS durationMs
 		FROM Playlist P
 		         JOIN PlaylistTrack PT ON P.id = PT.playlist_id
 		         JOIN Track T ON PT.track_id = T.id
 		         JOIN Album A ON T.album_id = A.id
 		         JOIN TrackArtist TA ON T.id = TA.track_id
 		         JOIN Artist A2 ON TA.artist_id = A2.id
-		WHERE P.name LIKE 'Starred%'
+		WHERE P.id IN (@PlaylistIds)
 		  AND (A2.name LIKE '%' || @Query || '%' OR T.name LIKE '%' || @Query || '%' OR A.name LIKE '%' || @Query || '%')
 		GROUP BY P.name, T.id, A.id, PT.added_at, T.track_number
 		ORDER BY P.name, A.id, PT.added_at, T.track_number
 	*/
-	sqlRows, err := database.Query(
-		"SELECT P.name AS playlistName, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists FROM Playlist P JOIN PlaylistTrack PT ON P.id = PT.playlist_id JOIN Track T ON PT.track_id = T.id JOIN Album A ON T.album_id = A.id JOIN TrackArtist TA ON T.id = TA.track_id JOIN Artist A2 ON TA.artist_id = A2.id WHERE P.name LIKE 'Starred%' AND (A2.name LIKE '%' || @Query || '%' OR T.name LIKE '%' || @Query || '%' OR A.name LIKE '%' || @Query || '%') GROUP BY P.name, T.id, A.id, PT.added_at, T.track_number ORDER BY P.name, A.name, PT.added_at, T.track_number",
-		sql.Named("Query", query))
+	indexedQuery, args := playlistIdsQuery(
+		"WITH Matches AS (SELECT id, rank FROM TrackSearch WHERE TrackSearch MATCH @Match) SELECT P.name AS playlistName, T.id AS trackId, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists, T.duration_ms AS durationMs FROM Matches M JOIN PlaylistTrack PT ON M.id = PT.track_id JOIN Playlist P ON PT.playlist_id = P.id JOIN Track T ON M.id = T.id JOIN Album A ON T.album_id = A.id JOIN TrackArtist TA ON T.id = TA.track_id JOIN Artist A2 ON TA.artist_id = A2.id WHERE P.id IN (@PlaylistIds) GROUP BY P.name, T.id, A.id, PT.added_at, T.track_number ORDER BY MIN(M.rank), P.name, PT.added_at, T.track_number",
+		playlists)
+	likeQuery, _ := playlistIdsQuery(
+		"SELECT P.name AS playlistName, T.id AS trackId, T.name AS trackName, A.name AS albumName, GROUP_CONCAT(A2.name, '; ') AS artists, T.duration_ms AS durationMs FROM Playlist P JOIN PlaylistTrack PT ON P.id = PT.playlist_id JOIN Track T ON PT.track_id = T.id JOIN Album A ON T.album_id = A.id JOIN TrackArtist TA ON T.id = TA.track_id JOIN Artist A2 ON TA.artist_id = A2.id WHERE P.id IN (@PlaylistIds) AND (A2.name LIKE '%' || @Query || '%' OR T.name LIKE '%' || @Query || '%' OR A.name LIKE '%' || @Query || '%') GROUP BY P.name, T.id, A.id, PT.added_at, T.track_number ORDER BY P.name, A.name, PT.added_at, T.track_number",
+		playlists)
+
+	statement, args, err := searchStatement(query, repo, indexedQuery, likeQuery, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sqlRows, err := queryPlaylistSet(ctx, playlists, repo, statement, args...)
 
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
+	defer sqlRows.Close()
+
 	var matches []models.StarredPlaylistMatch
 
 	for sqlRows.Next() {
-		var playlistName, trackName, albumName, artists string
+		var playlistName, trackId, trackName, albumName, artists string
+		var durationMs int
 
-		if err := sqlRows.Scan(&playlistName, &trackName, &albumName, &artists); err != nil {
-			panic(err)
+		if err := sqlRows.Scan(&playlistName, &trackId, &trackName, &albumName, &artists, &durationMs); err != nil {
+			return nil, err
 		}
 
 		matches = append(matches, models.StarredPlaylistMatch{
 			PlaylistName: playlistName,
+			TrackId:      trackId,
 			TrackName:    trackName,
 			AlbumName:    albumName,
 			Artists:      artists,
+			DurationMs:   durationMs,
 		})
 	}
 
-	return matches
+	if err := sqlRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
 }
 
-func SearchPlaylists(query string, db string) []models.SimpleIdentifier {
-	database, _ := sql.Open("sqlite3", db)
-	rows, err := database.Query(
-		"SELECT id, name FROM Playlist WHERE name LIKE '%' || @Query || '%' ORDER BY name",
-		sql.Named("Query", query))
+func SearchPlaylists(ctx context.Context, query string, repo *db.Repository) ([]models.SimpleIdentifier, error) {
+	playlists, _, err := searchPlaylists(ctx, query, noLimit, repo)
+
+	return playlists, err
+}
+
+// SearchPlaylists, returning at most limit matches and the number of matches before the limit
+func searchPlaylists(ctx context.Context, query string, limit int, repo *db.Repository) ([]models.SimpleIdentifier, int, error) {
+	/*
+		SELECT P.id, P.name, COUNT(*) OVER () AS total
+		FROM PlaylistSearch S
+		         JOIN Playlist P ON S.id = P.id
+		WHERE PlaylistSearch MATCH @Match
+		ORDER BY S.rank, P.name
+		LIMIT @Limit
+	*/
+	rows, err := searchQuery(ctx, query, repo,
+		"SELECT P.id, P.name, COUNT(*) OVER () AS total FROM PlaylistSearch S JOIN Playlist P ON S.id = P.id WHERE PlaylistSearch MATCH @Match ORDER BY S.rank, P.name LIMIT @Limit",
+		"SELECT id, name, COUNT(*) OVER () AS total FROM Playlist WHERE name LIKE '%' || @Query || '%' ORDER BY name LIMIT @Limit",
+		sql.Named("Limit", limit))
 
 	if err != nil {
-		panic(err)
+		return nil, 0, err
 	}
 
+	defer rows.Close()
+
 	var playlists []models.SimpleIdentifier
 
+	// number of matches before the limit, which every row repeats
+	total := 0
+
 	for rows.Next() {
 		var id string
 		var name string
 
-		if err := rows.Scan(&id, &name); err != nil {
-			panic(err)
+		if err := rows.Scan(&id, &name, &total); err != nil {
+			return nil, 0, err
 		}
 
 		playlists = append(playlists, models.SimpleIdentifier{Id: id, Name: name})
 	}
 
-	return playlists
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return playlists, total, nil
 }
 
-func GetDuplicateTracksInStarredPlaylists(db string) []models.DuplicateTrack {
+func SearchAlbums(ctx context.Context, query string, repo *db.Repository) ([]models.Album, error) {
+	albums, _, err := searchAlbums(ctx, query, noLimit, repo)
+
+	return albums, err
+}
+
+// SearchAlbums, returning at most limit matches and the number of matches before the limit
+func searchAlbums(ctx context.Context, query string, limit int, repo *db.Repository) ([]models.Album, int, error) {
 	/*
-		select tracks.playlists,
-		       tracks.track_id,
-		       T.name                     as track_name,
-		       GROUP_CONCAT(A.name, '; ') as artists,
-		       A2.name                    as album_name,
-		       A2.id                      as album_id
-		from (
-		         select pt.track_id,
-		                GROUP_CONCAT(p.name, '; ') as playlists
-		         from PlaylistTrack pt
-		                  join Playlist P on P.id = pt.playlist_id
-		                  join Track T on pt.track_id = T.id
-		         where p.name like 'Starred%'
-		         group by pt.track_id
-		         having count() > 1
-		     ) as tracks
-		         join Track T on T.id = tracks.track_id
-		         join TrackArtist TA on T.id = TA.track_id
-		         join Artist A on TA.artist_id = A.id
-		         join Album A2 on T.album_id = A2.id
-		group by T.id, A2.id
-		order by A2.id
+		WITH Matches AS (SELECT id, rank FROM AlbumSearch WHERE name MATCH @Match)
+		SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, GROUP_CONCAT(AR.name, '; ') AS artists, COUNT(*) OVER () AS total
+		FROM Matches M
+		         JOIN Album A ON M.id = A.id
+		         JOIN AlbumArtist AA ON A.id = AA.album_id
+		         JOIN Artist AR ON AA.artist_id = AR.id
+		GROUP BY A.id
+		ORDER BY MIN(M.rank), A.name, A.release_date
+		LIMIT @Limit
 	*/
-	query := "select tracks.playlists, T.name as track_name, GROUP_CONCAT(A.name, '; ') as artists, A2.name as album_name from ( select pt.track_id, GROUP_CONCAT(p.name, '; ') as playlists from PlaylistTrack pt join Playlist P on P.id = pt.playlist_id join Track T on pt.track_id = T.id where p.name like 'Starred%' group by pt.track_id having count() > 1	) as tracks join Track T on T.id = tracks.track_id join TrackArtist TA on T.id = TA.track_id join Artist A on TA.artist_id = A.id join Album A2 on T.album_id = A2.id group by T.id, A2.id order by A2.id"
+	// fallback when the search index hasn't been built
+	/*
+		SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, GROUP_CONCAT(AR.name, '; ') AS artists, COUNT(*) OVER () AS total
+		FROM Album A
+		         JOIN AlbumArtist AA ON A.id = AA.album_id
+		         JOIN Artist AR ON AA.artist_id = AR.id
+		WHERE A.name LIKE '%' || @Query || '%'
+		GROUP BY A.id
+		ORDER BY A.name, A.release_date
+		LIMIT @Limit
+	*/
+	rows, err := searchQuery(ctx, query, repo,
+		"WITH Matches AS (SELECT id, rank FROM AlbumSearch WHERE name MATCH @Match) SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, GROUP_CONCAT(AR.name, '; ') AS artists, COUNT(*) OVER () AS total FROM Matches M JOIN Album A ON M.id = A.id JOIN AlbumArtist AA ON A.id = AA.album_id JOIN Artist AR ON AA.artist_id = AR.id GROUP BY A.id ORDER BY MIN(M.rank), A.name, A.release_date LIMIT @Limit",
+		"SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, GROUP_CONCAT(AR.name, '; ') AS artists, COUNT(*) OVER () AS total FROM Album A JOIN AlbumArtist AA ON A.id = AA.album_id JOIN Artist AR ON AA.artist_id = AR.id WHERE A.name LIKE '%' || @Query || '%' GROUP BY A.id ORDER BY A.name, A.release_date LIMIT @Limit",
+		sql.Named("Limit", limit))
 
-	database, _ := sql.Open("sqlite3", db)
-	rows, err := database.Query(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	var albums []models.Album
+
+	// number of matches before the limit, which every row repeats
+	total := 0
+
+	for rows.Next() {
+		var id, name, releaseDate, albumType, artists string
+		var totalTracks int
+
+		if err := rows.Scan(&id, &name, &totalTracks, &releaseDate, &albumType, &artists, &total); err != nil {
+			return nil, 0, err
+		}
+
+		albums = append(albums, models.Album{
+			Id:          id,
+			Name:        name,
+			TotalTracks: totalTracks,
+			ReleaseDate: releaseDate,
+			AlbumType:   albumType,
+			Artists:     artists,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return albums, total, nil
+}
+
+func GetArtist(id string, repo *db.Repository) (models.SimpleIdentifier, error) {
+	/*
+		SELECT id, name
+		FROM Artist
+		WHERE id = @Id
+	*/
+	row, err := repo.QueryRow("SELECT id, name FROM Artist WHERE id = @Id", sql.Named("Id", id))
+
+	if err != nil {
+		return models.SimpleIdentifier{}, err
+	}
+
+	var artist models.SimpleIdentifier
+
+	err = row.Scan(&artist.Id, &artist.Name)
+
+	return artist, err
+}
+
+func GetAlbum(id string, repo *db.Repository) (models.Album, error) {
+	/*
+		SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists
+		FROM Album A
+		         LEFT JOIN AlbumArtist AA ON A.id = AA.album_id
+		         LEFT JOIN Artist AR ON AA.artist_id = AR.id
+		WHERE A.id = @Id
+		GROUP BY A.id
+	*/
+	row, err := repo.QueryRow(
+		"SELECT A.id, A.name, A.total_tracks, A.release_date, A.album_type, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists FROM Album A LEFT JOIN AlbumArtist AA ON A.id = AA.album_id LEFT JOIN Artist AR ON AA.artist_id = AR.id WHERE A.id = @Id GROUP BY A.id",
+		sql.Named("Id", id))
 
 	if err != nil {
-		panic(err)
+		return models.Album{}, err
 	}
 
-	var tracks []models.DuplicateTrack
+	var album models.Album
+
+	err = row.Scan(&album.Id, &album.Name, &album.TotalTracks, &album.ReleaseDate, &album.AlbumType, &album.Artists)
+
+	return album, err
+}
+
+func GetAlbumTracks(albumId string, repo *db.Repository) ([]models.Track, error) {
+	/*
+		SELECT T.id,
+		       T.name,
+		       T.track_number,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       IFNULL((SELECT GROUP_CONCAT(P.name, '; ')
+		               FROM Playlist P
+		               WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id)), '') AS playlists
+		FROM Track T
+		WHERE T.album_id = @Id
+		ORDER BY T.track_number
+	*/
+	rows, err := repo.Query(
+		"SELECT T.id, T.name, T.track_number, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, IFNULL((SELECT GROUP_CONCAT(P.name, '; ') FROM Playlist P WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id)), '') AS playlists FROM Track T WHERE T.album_id = @Id ORDER BY T.track_number",
+		sql.Named("Id", albumId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tracks []models.Track
 
 	for rows.Next() {
-		var playlists, trackName, artists, albumName string
+		var id, name, artists, playlists string
+		var trackNumber int
 
-		if err := rows.Scan(&playlists, &trackName, &artists, &albumName); err != nil {
-			panic(err)
+		if err := rows.Scan(&id, &name, &trackNumber, &artists, &playlists); err != nil {
+			return nil, err
 		}
 
-		tracks = append(tracks, models.DuplicateTrack{
-			Playlists: playlists,
-			TrackName: trackName,
-			Artists:   artists,
-			AlbumName: albumName,
+		tracks = append(tracks, models.Track{
+			Id:          id,
+			Name:        name,
+			TrackNumber: trackNumber,
+			Artists:     artists,
+			Playlists:   playlists,
 		})
 	}
 
-	return tracks
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+func SearchTracks(ctx context.Context, query string, repo *db.Repository) ([]models.Track, error) {
+	tracks, _, err := searchTracks(ctx, query, noLimit, repo)
+
+	return tracks, err
+}
+
+// SearchTracks, returning at most limit matches and the number of matches before the limit
+func searchTracks(ctx context.Context, query string, limit int, repo *db.Repository) ([]models.Track, int, error) {
+	/*
+		WITH Matches AS (SELECT id, rank FROM TrackSearch WHERE name MATCH @Match)
+		SELECT T.id, T.name, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists, A.id, A.name, A.release_date, T.duration_ms, COUNT(*) OVER () AS total
+		FROM Matches M
+		         JOIN Track T ON M.id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		         LEFT JOIN TrackArtist TA ON T.id = TA.track_id
+		         LEFT JOIN Artist AR ON TA.artist_id = AR.id
+		GROUP BY T.id
+		ORDER BY MIN(M.rank), T.name, A.release_date
+		LIMIT @Limit
+	*/
+	// fallback when the search index hasn't been built
+	/*
+		SELECT T.id, T.name, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists, A.id, A.name, A.release_date, T.duration_ms, COUNT(*) OVER () AS total
+		FROM Track T
+		         JOIN Album A ON T.album_id = A.id
+		         LEFT JOIN TrackArtist TA ON T.id = TA.track_id
+		         LEFT JOIN Artist AR ON TA.artist_id = AR.id
+		WHERE T.name LIKE '%' || @Query || '%'
+		GROUP BY T.id
+		ORDER BY T.name, A.release_date
+		LIMIT @Limit
+	*/
+	rows, err := searchQuery(ctx, query, repo,
+		"WITH Matches AS (SELECT id, rank FROM TrackSearch WHERE name MATCH @Match) SELECT T.id, T.name, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists, A.id, A.name, A.release_date, T.duration_ms, COUNT(*) OVER () AS total FROM Matches M JOIN Track T ON M.id = T.id JOIN Album A ON T.album_id = A.id LEFT JOIN TrackArtist TA ON T.id = TA.track_id LEFT JOIN Artist AR ON TA.artist_id = AR.id GROUP BY T.id ORDER BY MIN(M.rank), T.name, A.release_date LIMIT @Limit",
+		"SELECT T.id, T.name, IFNULL(GROUP_CONCAT(AR.name, '; '), '') AS artists, A.id, A.name, A.release_date, T.duration_ms, COUNT(*) OVER () AS total FROM Track T JOIN Album A ON T.album_id = A.id LEFT JOIN TrackArtist TA ON T.id = TA.track_id LEFT JOIN Artist AR ON TA.artist_id = AR.id WHERE T.name LIKE '%' || @Query || '%' GROUP BY T.id ORDER BY T.name, A.release_date LIMIT @Limit",
+		sql.Named("Limit", limit))
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	var tracks []models.Track
+
+	// number of matches before the limit, which every row repeats
+	total := 0
+
+	for rows.Next() {
+		var id, name, artists, albumId, albumName, releaseDate string
+		var durationMs int
+
+		if err := rows.Scan(&id, &name, &artists, &albumId, &albumName, &releaseDate, &durationMs, &total); err != nil {
+			return nil, 0, err
+		}
+
+		tracks = append(tracks, models.Track{
+			Id:          id,
+			Name:        name,
+			Artists:     artists,
+			AlbumId:     albumId,
+			AlbumName:   albumName,
+			ReleaseDate: releaseDate,
+			DurationMs:  durationMs,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return tracks, total, nil
+}
+
+func GetTrack(id string, repo *db.Repository) (models.SimpleIdentifier, error) {
+	/*
+		SELECT id, name
+		FROM Track
+		WHERE id = @Id
+	*/
+	row, err := repo.QueryRow("SELECT id, name FROM Track WHERE id = @Id", sql.Named("Id", id))
+
+	if err != nil {
+		return models.SimpleIdentifier{}, err
+	}
+
+	var track models.SimpleIdentifier
+
+	err = row.Scan(&track.Id, &track.Name)
+
+	return track, err
+}
+
+func GetPlaylistsContainingTrack(trackId string, repo *db.Repository) ([]models.PlaylistAppearance, error) {
+	/*
+		SELECT P.id, P.name, PT.added_at
+		FROM PlaylistTrack PT
+		         JOIN Playlist P ON PT.playlist_id = P.id
+		WHERE PT.track_id = @Id
+		ORDER BY PT.added_at, P.name
+	*/
+	rows, err := repo.Query(
+		"SELECT P.id, P.name, PT.added_at FROM PlaylistTrack PT JOIN Playlist P ON PT.playlist_id = P.id WHERE PT.track_id = @Id ORDER BY PT.added_at, P.name",
+		sql.Named("Id", trackId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var appearances []models.PlaylistAppearance
+
+	for rows.Next() {
+		var id, name, addedAt string
+
+		if err := rows.Scan(&id, &name, &addedAt); err != nil {
+			return nil, err
+		}
+
+		appearances = append(appearances, models.PlaylistAppearance{
+			PlaylistId:   id,
+			PlaylistName: name,
+			AddedAt:      addedAt,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return appearances, nil
+}
+
+func GetPlaylist(id string, repo *db.Repository) (models.SimpleIdentifier, error) {
+	/*
+		SELECT id, name
+		FROM Playlist
+		WHERE id = @Id
+	*/
+	row, err := repo.QueryRow("SELECT id, name FROM Playlist WHERE id = @Id", sql.Named("Id", id))
+
+	if err != nil {
+		return models.SimpleIdentifier{}, err
+	}
+
+	var playlist models.SimpleIdentifier
+
+	err = row.Scan(&playlist.Id, &playlist.Name)
+
+	return playlist, err
+}
+
+func GetPlaylistTracks(playlistId string, repo *db.Repository) ([]models.Track, error) {
+	/*
+		SELECT T.id,
+		       T.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       A.id,
+		       A.name,
+		       T.duration_ms,
+		       PT.added_at
+		FROM PlaylistTrack PT
+		         JOIN Track T ON PT.track_id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		WHERE PT.playlist_id = @Id
+		ORDER BY PT.added_at
+	*/
+	rows, err := repo.Query(
+		"SELECT T.id, T.name, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, A.id, A.name, T.duration_ms, PT.added_at FROM PlaylistTrack PT JOIN Track T ON PT.track_id = T.id JOIN Album A ON T.album_id = A.id WHERE PT.playlist_id = @Id ORDER BY PT.added_at",
+		sql.Named("Id", playlistId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tracks []models.Track
+
+	for rows.Next() {
+		var id, name, artists, albumId, albumName, addedAt string
+		var durationMs int
+
+		if err := rows.Scan(&id, &name, &artists, &albumId, &albumName, &durationMs, &addedAt); err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, models.Track{
+			Id:         id,
+			Name:       name,
+			Artists:    artists,
+			AlbumId:    albumId,
+			AlbumName:  albumName,
+			DurationMs: durationMs,
+			// tracks are ordered by when they were added
+			Position: len(tracks) + 1,
+			AddedAt:  addedAt,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// Get Tracks the Artist is credited on, plus every Track on the Artist's Albums
+func GetTracksByArtist(artist *models.SimpleIdentifier, repo *db.Repository) ([]models.Track, error) {
+	/*
+		SELECT T.id,
+		       T.name,
+		       T.track_number,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       IFNULL((SELECT GROUP_CONCAT(P.name, '; ')
+		               FROM Playlist P
+		               WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id)), '') AS playlists,
+		       A.id,
+		       A.name,
+		       A.release_date,
+		       T.duration_ms
+		FROM Track T
+		         JOIN Album A ON T.album_id = A.id
+		WHERE T.id IN (SELECT track_id FROM TrackArtist WHERE artist_id = @Id)
+		   OR T.album_id IN (SELECT album_id FROM AlbumArtist WHERE artist_id = @Id)
+		ORDER BY A.name, T.track_number
+	*/
+	rows, err := repo.Query(
+		"SELECT T.id, T.name, T.track_number, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, IFNULL((SELECT GROUP_CONCAT(P.name, '; ') FROM Playlist P WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id)), '') AS playlists, A.id, A.name, A.release_date, T.duration_ms FROM Track T JOIN Album A ON T.album_id = A.id WHERE T.id IN (SELECT track_id FROM TrackArtist WHERE artist_id = @Id) OR T.album_id IN (SELECT album_id FROM AlbumArtist WHERE artist_id = @Id) ORDER BY A.name, T.track_number",
+		sql.Named("Id", artist.Id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tracks []models.Track
+
+	for rows.Next() {
+		var id, name, artists, playlists, albumId, albumName, releaseDate string
+		var trackNumber, durationMs int
+
+		if err := rows.Scan(&id, &name, &trackNumber, &artists, &playlists, &albumId, &albumName, &releaseDate, &durationMs); err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, models.Track{
+			Id:          id,
+			Name:        name,
+			TrackNumber: trackNumber,
+			Artists:     artists,
+			Playlists:   playlists,
+			AlbumId:     albumId,
+			AlbumName:   albumName,
+			ReleaseDate: releaseDate,
+			DurationMs:  durationMs,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+// Search Artists, Albums, Tracks and Playlists at once, returning at most limit matches of each and the number of matches of each
+func SearchEverything(ctx context.Context, query string, limit int, repo *db.Repository) (models.SearchResults, error) {
+	var results models.SearchResults
+	var err error
+
+	// an empty query would match every row of every table
+	if strings.TrimSpace(query) == "" {
+		return results, ErrEmptyQuery
+	}
+
+	if results.Artists, results.ArtistCount, err = searchArtists(ctx, query, limit, repo); err != nil {
+		return results, err
+	}
+
+	if results.Albums, results.AlbumCount, err = searchAlbums(ctx, query, limit, repo); err != nil {
+		return results, err
+	}
+
+	if results.Tracks, results.TrackCount, err = searchTracks(ctx, query, limit, repo); err != nil {
+		return results, err
+	}
+
+	if results.Playlists, results.PlaylistCount, err = searchPlaylists(ctx, query, limit, repo); err != nil {
+		return results, err
+	}
+
+	return results, nil
 }
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Find tracks that are in more than one of the playlists, or more than once in the same playlist.
+// Each duplicate lists every copy of the track with its position and when it was added.
+func FindDuplicateTracks(playlists PlaylistSet, repo *db.Repository) ([]models.DuplicateTrack, error) {
+	if playlists.Empty() {
+		return nil, nil
+	}
+
+	// positions are numbered in added_at order, matching GetPlaylistTracks
+	/*
+		WITH Positions AS (SELECT PT.playlist_id,
+		                          PT.track_id,
+		                          PT.added_at,
+		                          ROW_NUMBER() OVER (PARTITION BY PT.playlist_id ORDER BY PT.added_at) AS position
+		                   FROM PlaylistTrack PT
+		                   WHERE PT.playlist_id IN (@PlaylistIds)),
+		     Duplicates AS (SELECT track_id FROM Positions GROUP BY track_id HAVING COUNT(*) > 1)
+		SELECT T.id,
+		       T.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       A.name,
+		       P.id,
+		       P.name,
+		       PO.position,
+		       PO.added_at
+		FROM Duplicates D
+		         JOIN Positions PO ON D.track_id = PO.track_id
+		         JOIN Track T ON D.track_id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		         JOIN Playlist P ON PO.playlist_id = P.id
+		ORDER BY A.name, T.name, T.id, P.name, PO.position
+	*/
+	query, args := playlistIdsQuery(
+		"WITH Positions AS (SELECT PT.playlist_id, PT.track_id, PT.added_at, ROW_NUMBER() OVER (PARTITION BY PT.playlist_id ORDER BY PT.added_at) AS position FROM PlaylistTrack PT WHERE PT.playlist_id IN (@PlaylistIds)), Duplicates AS (SELECT track_id FROM Positions GROUP BY track_id HAVING COUNT(*) > 1) SELECT T.id, T.name, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, A.name, P.id, P.name, PO.position, PO.added_at FROM Duplicates D JOIN Positions PO ON D.track_id = PO.track_id JOIN Track T ON D.track_id = T.id JOIN Album A ON T.album_id = A.id JOIN Playlist P ON PO.playlist_id = P.id ORDER BY A.name, T.name, T.id, P.name, PO.position",
+		playlists)
+
+	rows, err := queryPlaylistSet(context.Background(), playlists, repo, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tracks []models.DuplicateTrack
+
+	for rows.Next() {
+		var track models.DuplicateTrack
+		var appearance models.PlaylistAppearance
+
+		if err := rows.Scan(&track.TrackId, &track.TrackName, &track.Artists, &track.AlbumName, &appearance.PlaylistId, &appearance.PlaylistName, &appearance.Position, &appearance.AddedAt); err != nil {
+			return nil, err
+		}
+
+		// rows are ordered by track, so each track's copies are consecutive
+		if len(tracks) == 0 || tracks[len(tracks)-1].TrackId != track.TrackId {
+			tracks = append(tracks, track)
+		}
+
+		last := &tracks[len(tracks)-1]
+		last.Occurrences = append(last.Occurrences, appearance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tracks {
+		tracks[i].Playlists = summarizeOccurrences(tracks[i].Occurrences)
+	}
+
+	return tracks, nil
+}
+
+// Summarize where the copies of a track are, e.g. "Starred 2020 #3 (2020-05-01); Starred 2021 #12 (2021-02-14)"
+func summarizeOccurrences(occurrences []models.PlaylistAppearance) string {
+	summaries := make([]string, len(occurrences))
+
+	for i, o := range occurrences {
+		date := o.AddedAt
+		if len(date) > 10 {
+			date = date[:10]
+		}
+
+		summaries[i] = fmt.Sprintf("%s #%d (%s)", o.PlaylistName, o.Position, date)
+	}
+
+	return strings.Join(summaries, "; ")
+}
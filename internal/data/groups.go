@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Get every playlist, sorted by name
+func GetPlaylists(repo *db.Repository) ([]models.SimpleIdentifier, error) {
+	/*
+		SELECT id, name
+		FROM Playlist
+		ORDER BY name
+	*/
+	rows, err := repo.Query("SELECT id, name FROM Playlist ORDER BY name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var playlists []models.SimpleIdentifier
+
+	for rows.Next() {
+		var playlist models.SimpleIdentifier
+
+		if err := rows.Scan(&playlist.Id, &playlist.Name); err != nil {
+			return nil, err
+		}
+
+		playlists = append(playlists, playlist)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return playlists, nil
+}
+
+// Get the playlists in the group, sorted by name.
+// The group's patterns may be regexps, which SQLite can't match, so the playlists are filtered here.
+func GetPlaylistGroupPlaylists(group models.PlaylistGroup, repo *db.Repository) ([]models.SimpleIdentifier, error) {
+	playlists, err := GetPlaylists(repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var members []models.SimpleIdentifier
+
+	for _, p := range playlists {
+		if group.Contains(p) {
+			members = append(members, p)
+		}
+	}
+
+	return members, nil
+}
+
+// Get the ids of the playlists in the group
+func playlistGroupIds(group models.PlaylistGroup, repo *db.Repository) ([]string, error) {
+	playlists, err := GetPlaylistGroupPlaylists(group, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return playlistIds(playlists), nil
+}
+
+func playlistIds(playlists []models.SimpleIdentifier) []string {
+	ids := make([]string, len(playlists))
+	for i, p := range playlists {
+		ids[i] = p.Id
+	}
+
+	return ids
+}
+
+// The playlists to run a query against: every playlist if All is set, otherwise the playlists with the Ids
+type PlaylistSet struct {
+	All bool
+	Ids []string
+}
+
+// Every playlist
+var AllPlaylists = PlaylistSet{All: true}
+
+// The set of the playlists
+func PlaylistsOf(playlists []models.SimpleIdentifier) PlaylistSet {
+	return PlaylistSet{Ids: playlistIds(playlists)}
+}
+
+// Whether the set has no playlists
+func (s PlaylistSet) Empty() bool {
+	return !s.All && len(s.Ids) == 0
+}
+
+// a "column IN (@PlaylistIds)" condition
+var playlistIdsCondition = regexp.MustCompile(`[\w.]+ IN \(@PlaylistIds\)`)
+
+// Expand each @PlaylistIds list in the query into a named parameter per id,
+// or drop each "column IN (@PlaylistIds)" condition if the set has every playlist.
+// The set must not be empty, since "IN ()" isn't valid SQL.
+func playlistIdsQuery(query string, playlists PlaylistSet) (string, []interface{}) {
+	if playlists.All {
+		return playlistIdsCondition.ReplaceAllString(query, "1"), nil
+	}
+
+	names := make([]string, len(playlists.Ids))
+	args := make([]interface{}, len(playlists.Ids))
+
+	for i, id := range playlists.Ids {
+		name := fmt.Sprintf("PlaylistId%d", i)
+		names[i] = "@" + name
+		args[i] = sql.Named(name, id)
+	}
+
+	return strings.ReplaceAll(query, "@PlaylistIds", strings.Join(names, ", ")), args
+}
+
+// Run a query expanded by playlistIdsQuery.
+// Its text is the same on every call for every playlist, so that query is prepared once,
+// but a list of ids makes a new text for each number of ids, so those queries aren't prepared.
+func queryPlaylistSet(ctx context.Context, playlists PlaylistSet, repo *db.Repository, query string, args ...interface{}) (*sql.Rows, error) {
+	if playlists.All {
+		return repo.QueryContext(ctx, query, args...)
+	}
+
+	return repo.QueryUnprepared(ctx, query, args...)
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestPlaylistIdsQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		playlists PlaylistSet
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			"one id",
+			"SELECT * FROM PlaylistTrack PT WHERE PT.playlist_id IN (@PlaylistIds)",
+			PlaylistSet{Ids: []string{"p1"}},
+			"SELECT * FROM PlaylistTrack PT WHERE PT.playlist_id IN (@PlaylistId0)",
+			[]interface{}{sql.Named("PlaylistId0", "p1")},
+		},
+		{
+			"several ids in several lists",
+			"SELECT * FROM Playlist P WHERE P.id IN (@PlaylistIds) AND P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE playlist_id IN (@PlaylistIds))",
+			PlaylistSet{Ids: []string{"p1", "p2"}},
+			"SELECT * FROM Playlist P WHERE P.id IN (@PlaylistId0, @PlaylistId1) AND P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE playlist_id IN (@PlaylistId0, @PlaylistId1))",
+			[]interface{}{sql.Named("PlaylistId0", "p1"), sql.Named("PlaylistId1", "p2")},
+		},
+		{
+			"every playlist drops the conditions",
+			"SELECT * FROM Playlist P WHERE P.id IN (@PlaylistIds) AND P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE playlist_id IN (@PlaylistIds))",
+			AllPlaylists,
+			"SELECT * FROM Playlist P WHERE 1 AND P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE 1)",
+			nil,
+		},
+		{
+			"every playlist ignores the ids",
+			"SELECT * FROM PlaylistTrack WHERE playlist_id IN (@PlaylistIds)",
+			PlaylistSet{All: true, Ids: []string{"p1"}},
+			"SELECT * FROM PlaylistTrack WHERE 1",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := playlistIdsQuery(tt.query, tt.playlists)
+
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPlaylistSetEmpty(t *testing.T) {
+	tests := []struct {
+		name      string
+		playlists PlaylistSet
+		want      bool
+	}{
+		{"no ids", PlaylistSet{}, true},
+		{"empty ids", PlaylistSet{Ids: []string{}}, true},
+		{"some ids", PlaylistSet{Ids: []string{"p1"}}, false},
+		{"every playlist", AllPlaylists, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.playlists.Empty(); got != tt.want {
+				t.Errorf("Empty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+)
+
+// Statements that (re)build the FTS5 full-text search index.
+// The index is made up of one table per searchable entity, each keyed by the entity's id.
+// Requires the sqlite3 driver to be built with the "sqlite_fts5" tag.
+var searchIndexStatements = []string{
+	"DROP TABLE IF EXISTS ArtistSearch",
+	"DROP TABLE IF EXISTS AlbumSearch",
+	"DROP TABLE IF EXISTS TrackSearch",
+	"DROP TABLE IF EXISTS PlaylistSearch",
+	"CREATE VIRTUAL TABLE ArtistSearch USING fts5(id UNINDEXED, name, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')",
+	"CREATE VIRTUAL TABLE AlbumSearch USING fts5(id UNINDEXED, name, artists, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')",
+	"CREATE VIRTUAL TABLE TrackSearch USING fts5(id UNINDEXED, name, album, artists, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')",
+	"CREATE VIRTUAL TABLE PlaylistSearch USING fts5(id UNINDEXED, name, tokenize = 'unicode61 remove_diacritics 2', prefix = '2 3')",
+	"INSERT INTO ArtistSearch (id, name) SELECT id, name FROM Artist",
+	/*
+		INSERT INTO AlbumSearch (id, name, artists)
+		SELECT A.id,
+		       A.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, ' ')
+		               FROM AlbumArtist AA
+		                        JOIN Artist AR ON AA.artist_id = AR.id
+		               WHERE AA.album_id = A.id), '')
+		FROM Album A
+	*/
+	"INSERT INTO AlbumSearch (id, name, artists) SELECT A.id, A.name, IFNULL((SELECT GROUP_CONCAT(AR.name, ' ') FROM AlbumArtist AA JOIN Artist AR ON AA.artist_id = AR.id WHERE AA.album_id = A.id), '') FROM Album A",
+	/*
+		INSERT INTO TrackSearch (id, name, album, artists)
+		SELECT T.id,
+		       T.name,
+		       A.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, ' ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '')
+		FROM Track T
+		         JOIN Album A ON T.album_id = A.id
+	*/
+	"INSERT INTO TrackSearch (id, name, album, artists) SELECT T.id, T.name, A.name, IFNULL((SELECT GROUP_CONCAT(AR.name, ' ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') FROM Track T JOIN Album A ON T.album_id = A.id",
+	"INSERT INTO PlaylistSearch (id, name) SELECT id, name FROM Playlist",
+}
+
+// Build the full-text search index, replacing it if it already exists
+func BuildSearchIndex(repo *db.Repository) error {
+	available, err := hasFTS5(repo)
+
+	if err != nil {
+		return err
+	}
+
+	if !available {
+		return fmt.Errorf("FTS5 is not available, rebuild with '-tags sqlite_fts5'")
+	}
+
+	tx, err := repo.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range searchIndexStatements {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+
+	// check for the rebuilt tables on the next search
+	forgetSearchIndex(repo)
+
+	return err
+}
+
+// Check whether the sqlite3 driver was built with FTS5 support
+func hasFTS5(repo *db.Repository) (bool, error) {
+	row, err := repo.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')")
+
+	if err != nil {
+		return false, err
+	}
+
+	var available bool
+	err = row.Scan(&available)
+
+	return available, err
+}
+
+// Whether each Repository's search index can be queried, so that searches don't have to check first.
+// Only BuildSearchIndex changes the index while the app is running.
+var searchIndexes = struct {
+	sync.Mutex
+	indexed map[*db.Repository]bool
+}{indexed: make(map[*db.Repository]bool)}
+
+// Check whether the full-text search index has been built and can be queried.
+// A DB indexed by a build with FTS5 can still be opened by a build without it.
+// The result is cached until the index is rebuilt.
+func HasSearchIndex(repo *db.Repository) (bool, error) {
+	searchIndexes.Lock()
+	defer searchIndexes.Unlock()
+
+	if indexed, ok := searchIndexes.indexed[repo]; ok {
+		return indexed, nil
+	}
+
+	indexed, err := querySearchIndex(repo)
+
+	if err != nil {
+		return false, err
+	}
+
+	searchIndexes.indexed[repo] = indexed
+
+	return indexed, nil
+}
+
+// Drop the cached result of HasSearchIndex
+func forgetSearchIndex(repo *db.Repository) {
+	searchIndexes.Lock()
+	defer searchIndexes.Unlock()
+
+	delete(searchIndexes.indexed, repo)
+}
+
+// Query the DB for whether the search index can be used
+func querySearchIndex(repo *db.Repository) (bool, error) {
+	available, err := hasFTS5(repo)
+
+	if err != nil || !available {
+		return false, err
+	}
+
+	row, err := repo.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('ArtistSearch', 'AlbumSearch', 'TrackSearch', 'PlaylistSearch')")
+
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+
+	if err := row.Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count == 4, nil
+}
+
+// Convert the user's query into an FTS5 MATCH expression, prefix matching each word.
+// Returns false if the query can't be run against the index,
+// either because the index hasn't been built or because the query has no words to match.
+func matchExpression(query string, repo *db.Repository) (string, bool, error) {
+	words := strings.Fields(query)
+
+	if len(words) == 0 {
+		return "", false, nil
+	}
+
+	indexed, err := HasSearchIndex(repo)
+
+	if err != nil || !indexed {
+		return "", false, err
+	}
+
+	for i, word := range words {
+		// quote each word so that FTS5 operators and punctuation are treated as text
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+
+	return strings.Join(words, " "), true, nil
+}
+
+// Run the indexed query if the search index can be used, otherwise fall back to the LIKE query.
+// args are passed to either query, after the query's @Match or @Query parameter.
+func searchQuery(ctx context.Context, query string, repo *db.Repository, indexedQuery string, likeQuery string, args ...interface{}) (*sql.Rows, error) {
+	statement, args, err := searchStatement(query, repo, indexedQuery, likeQuery, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.QueryContext(ctx, statement, args...)
+}
+
+// Choose the indexed query if the search index can be used, otherwise the LIKE query,
+// and prepend the query's @Match or @Query parameter to args
+func searchStatement(query string, repo *db.Repository, indexedQuery string, likeQuery string, args ...interface{}) (string, []interface{}, error) {
+	match, indexed, err := matchExpression(query, repo)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if indexed {
+		return indexedQuery, append([]interface{}{sql.Named("Match", match)}, args...), nil
+	}
+
+	return likeQuery, append([]interface{}{sql.Named("Query", query)}, args...), nil
+}
@@ -0,0 +1,306 @@
+package data
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Largest difference in duration, in milliseconds, between tracks that are considered the same recording
+const SimilarDurationTolerance = 3000
+
+var (
+	// trailing "(...)" or "[...]", e.g. "(feat. Thom Yorke)" or "[Remastered]"
+	parentheticalSuffix = regexp.MustCompile(`\s*[(\[][^()\[\]]*[)\]]\s*$`)
+	// words that name a release of the recording in a " - ..." suffix, e.g. " - Remastered 2009", " - 2011 Re-master" or " - Single Version"
+	releaseWords = regexp.MustCompile(`(?i)\b(re-?master|re-?mastered|version|single|mono|stereo|edit)\b`)
+	whitespace   = regexp.MustCompile(`\s+`)
+)
+
+// Strip the last " - ..." suffix from the name if it names a release of the recording.
+// The suffix is everything after the last " - ", so it can contain hyphens within words, e.g. "Re-master".
+func stripReleaseSuffix(name string) string {
+	trimmed := strings.TrimSpace(name)
+	i := strings.LastIndex(trimmed, " - ")
+
+	if i < 0 || !releaseWords.MatchString(trimmed[i+len(" - "):]) {
+		return name
+	}
+
+	return trimmed[:i]
+}
+
+// Normalize a track name so that releases of the same recording share it,
+// e.g. "Karma Police - Remastered 2009" and "Karma Police" both become "karma police"
+func normalizeTrackName(name string) string {
+	for {
+		stripped := parentheticalSuffix.ReplaceAllString(name, "")
+		stripped = stripReleaseSuffix(stripped)
+
+		// keep the name if stripping would leave nothing, e.g. for "(What's the Story) Morning Glory?"
+		if stripped == name || strings.TrimSpace(stripped) == "" {
+			break
+		}
+
+		name = stripped
+	}
+
+	return strings.TrimSpace(whitespace.ReplaceAllString(strings.ToLower(name), " "))
+}
+
+// A track and the sorted ids of its artists, for grouping
+type similarCandidate struct {
+	track     models.Track
+	artistIds []string
+}
+
+// Find groups of different tracks in the playlists that are probably the same recording:
+// their normalized names match, they share an artist and their durations are within toleranceMs of each other.
+func FindSimilarTracks(playlists PlaylistSet, toleranceMs int, repo *db.Repository) ([]models.SimilarTracks, error) {
+	if playlists.Empty() {
+		return nil, nil
+	}
+
+	/*
+		SELECT T.id,
+		       T.name,
+		       IFNULL((SELECT GROUP_CONCAT(AR.name, '; ')
+		               FROM TrackArtist TA
+		                        JOIN Artist AR ON TA.artist_id = AR.id
+		               WHERE TA.track_id = T.id), '') AS artists,
+		       IFNULL((SELECT GROUP_CONCAT(TA.artist_id, ',')
+		               FROM TrackArtist TA
+		               WHERE TA.track_id = T.id), '') AS artistIds,
+		       A.id,
+		       A.name,
+		       T.duration_ms,
+		       IFNULL((SELECT GROUP_CONCAT(P.name, '; ')
+		               FROM Playlist P
+		               WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id AND playlist_id IN (@PlaylistIds))), '') AS playlists
+		FROM Track T
+		         JOIN Album A ON T.album_id = A.id
+		WHERE T.id IN (SELECT track_id FROM PlaylistTrack WHERE playlist_id IN (@PlaylistIds))
+	*/
+	query, args := playlistIdsQuery(
+		"SELECT T.id, T.name, IFNULL((SELECT GROUP_CONCAT(AR.name, '; ') FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id WHERE TA.track_id = T.id), '') AS artists, IFNULL((SELECT GROUP_CONCAT(TA.artist_id, ',') FROM TrackArtist TA WHERE TA.track_id = T.id), '') AS artistIds, A.id, A.name, T.duration_ms, IFNULL((SELECT GROUP_CONCAT(P.name, '; ') FROM Playlist P WHERE P.id IN (SELECT playlist_id FROM PlaylistTrack WHERE track_id = T.id AND playlist_id IN (@PlaylistIds))), '') AS playlists FROM Track T JOIN Album A ON T.album_id = A.id WHERE T.id IN (SELECT track_id FROM PlaylistTrack WHERE playlist_id IN (@PlaylistIds))",
+		playlists)
+
+	rows, err := queryPlaylistSet(context.Background(), playlists, repo, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	// candidates grouped by normalized name
+	candidates := make(map[string][]similarCandidate)
+
+	for rows.Next() {
+		var c similarCandidate
+		var artistIds string
+
+		if err := rows.Scan(&c.track.Id, &c.track.Name, &c.track.Artists, &artistIds, &c.track.AlbumId, &c.track.AlbumName, &c.track.DurationMs, &c.track.Playlists); err != nil {
+			return nil, err
+		}
+
+		// GROUP_CONCAT's order is undefined, so the ids are sorted to compare the sets of artists
+		if artistIds != "" {
+			c.artistIds = strings.Split(artistIds, ",")
+			sort.Strings(c.artistIds)
+		}
+
+		key := normalizeTrackName(c.track.Name)
+		candidates[key] = append(candidates[key], c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var groups []models.SimilarTracks
+
+	for key, group := range candidates {
+		for _, cluster := range clusterByDuration(group, toleranceMs) {
+			for _, related := range groupBySharedArtists(cluster) {
+				if len(related) > 1 {
+					groups = append(groups, similarTracks(key, related, toleranceMs))
+				}
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Key != groups[j].Key {
+			return groups[i].Key < groups[j].Key
+		}
+
+		return groups[i].Tracks[0].Artists < groups[j].Tracks[0].Artists
+	})
+
+	return groups, nil
+}
+
+// Split the candidates into clusters whose durations are all within toleranceMs of the cluster's shortest track,
+// so that no two tracks in a cluster differ by more than toleranceMs
+func clusterByDuration(candidates []similarCandidate, toleranceMs int) [][]similarCandidate {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].track.DurationMs < candidates[j].track.DurationMs })
+
+	var clusters [][]similarCandidate
+	start := 0
+
+	for i := 1; i <= len(candidates); i++ {
+		if i == len(candidates) || candidates[i].track.DurationMs-candidates[start].track.DurationMs > toleranceMs {
+			clusters = append(clusters, candidates[start:i])
+			start = i
+		}
+	}
+
+	return clusters
+}
+
+// Sort the candidates with the shortest name first,
+// which is usually the original release, e.g. "Karma Police" rather than "Karma Police - Remastered 2009"
+func sortByName(candidates []similarCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].track, candidates[j].track
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+
+		return a.Id < b.Id
+	})
+}
+
+// Split the candidates into groups of the candidates that share an artist with the group's first candidate,
+// taking the candidate with the shortest name first
+func groupBySharedArtists(candidates []similarCandidate) [][]similarCandidate {
+	sortByName(candidates)
+
+	var groups [][]similarCandidate
+
+	for remaining := candidates; len(remaining) > 0; {
+		group := []similarCandidate{remaining[0]}
+		var rest []similarCandidate
+
+		for _, c := range remaining[1:] {
+			if artistSimilarity(remaining[0].artistIds, c.artistIds) > 0 {
+				group = append(group, c)
+			} else {
+				rest = append(rest, c)
+			}
+		}
+
+		groups = append(groups, group)
+		remaining = rest
+	}
+
+	return groups
+}
+
+// Score each candidate against the one with the shortest name
+func similarTracks(key string, candidates []similarCandidate, toleranceMs int) models.SimilarTracks {
+	sortByName(candidates)
+
+	first := candidates[0]
+	group := models.SimilarTracks{Key: key}
+
+	for _, c := range candidates {
+		group.Tracks = append(group.Tracks, models.SimilarTrack{Track: c.track, Similarity: similarity(first, c, toleranceMs)})
+	}
+
+	return group
+}
+
+// Average of how alike the tracks' normalized names are, how alike their artists are and how close their durations are, from 0 to 1
+func similarity(a similarCandidate, b similarCandidate, toleranceMs int) float64 {
+	return (nameSimilarity(a.track.Name, b.track.Name) + artistSimilarity(a.artistIds, b.artistIds) + durationSimilarity(a.track.DurationMs, b.track.DurationMs, toleranceMs)) / 3
+}
+
+// How alike the names are once they're normalized, from 0 to 1,
+// so that e.g. "Karma Police" and "Karma Police - Remastered 2009" are the same
+func nameSimilarity(a string, b string) float64 {
+	nameA, nameB := []rune(normalizeTrackName(a)), []rune(normalizeTrackName(b))
+
+	longest := len(nameA)
+	if len(nameB) > longest {
+		longest = len(nameB)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(editDistance(nameA, nameB))/float64(longest)
+}
+
+// The Jaccard index of the sorted artist ids, from 0 to 1:
+// the number of artists on both tracks as a fraction of the artists on either
+func artistSimilarity(a []string, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// How close the durations are, from 1 if they're the same to 0 if they differ by toleranceMs or more
+func durationSimilarity(a int, b int, toleranceMs int) float64 {
+	if toleranceMs <= 0 {
+		return 1
+	}
+
+	diff := a - b
+	if diff < 0 {
+		diff = -diff
+	}
+
+	// clusters keep diff within toleranceMs, but the score must not go negative for tracks compared outside of one
+	return math.Max(0, 1-float64(diff)/float64(toleranceMs))
+}
+
+// Levenshtein distance between a and b
+func editDistance(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = models.Min(models.Min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
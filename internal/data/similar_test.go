@@ -0,0 +1,209 @@
+package data
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+func candidatesWithDurations(durations ...int) []similarCandidate {
+	var candidates []similarCandidate
+	for _, d := range durations {
+		candidates = append(candidates, similarCandidate{track: models.Track{DurationMs: d}})
+	}
+
+	return candidates
+}
+
+func TestClusterByDuration(t *testing.T) {
+	tests := []struct {
+		name        string
+		durations   []int
+		toleranceMs int
+		want        [][]int
+	}{
+		{"empty", nil, 3000, nil},
+		{"single track", []int{1000}, 3000, [][]int{{1000}}},
+		{"within tolerance", []int{1000, 3000, 4000}, 3000, [][]int{{1000, 3000, 4000}}},
+		{"exactly the tolerance apart", []int{0, 3000}, 3000, [][]int{{0, 3000}}},
+		{"beyond tolerance", []int{0, 3001}, 3000, [][]int{{0}, {3001}}},
+		{"unsorted input", []int{10000, 0, 1000}, 3000, [][]int{{0, 1000}, {10000}}},
+		{"chained tracks don't span more than the tolerance", []int{0, 2500, 5000}, 3000, [][]int{{0, 2500}, {5000}}},
+		{"chain split into several clusters", []int{0, 2000, 4000, 6000, 8000}, 3000, [][]int{{0, 2000}, {4000, 6000}, {8000}}},
+		{"zero tolerance", []int{1000, 1000, 1001}, 0, [][]int{{1000, 1000}, {1001}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			for _, cluster := range clusterByDuration(candidatesWithDurations(tt.durations...), tt.toleranceMs) {
+				var durations []int
+				for _, c := range cluster {
+					durations = append(durations, c.track.DurationMs)
+				}
+
+				got = append(got, durations)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clusterByDuration(%v, %d) = %v, want %v", tt.durations, tt.toleranceMs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTrackName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Karma Police", "karma police"},
+		{"  Karma   POLICE ", "karma police"},
+		{"Karma Police - Remastered 2009", "karma police"},
+		{"Karma Police - Remastered 2017", "karma police"},
+		{"Karma Police - 2011 Re-master", "karma police"},
+		{"Karma Police - 2011 Re-Mastered Version", "karma police"},
+		{"Karma Police - Single Version", "karma police"},
+		{"Karma Police - Mono", "karma police"},
+		{"Karma Police (Remastered)", "karma police"},
+		{"Karma Police [Live]", "karma police"},
+		{"Sour Times (feat. Thom Yorke) - Remastered", "sour times"},
+		{"Sour Times - Radio Edit (feat. Thom Yorke)", "sour times"},
+		{"Paranoid Android - Live at Glastonbury", "paranoid android - live at glastonbury"},
+		{"Part 1 - Part 2", "part 1 - part 2"},
+		{"Part 1 - Part 2 - Remastered", "part 1 - part 2"},
+		{"Re-master - Remastered", "re-master"},
+		{"(What's the Story) Morning Glory?", "(what's the story) morning glory?"},
+		{"(Remastered)", "(remastered)"},
+		{"Single", "single"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeTrackName(tt.name); got != tt.want {
+				t.Errorf("normalizeTrackName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func candidate(name string, durationMs int, artistIds ...string) similarCandidate {
+	return similarCandidate{track: models.Track{Id: name, Name: name, DurationMs: durationMs}, artistIds: artistIds}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name        string
+		a           similarCandidate
+		b           similarCandidate
+		toleranceMs int
+		want        float64
+	}{
+		{"identical", candidate("Karma Police", 1000, "ar1"), candidate("Karma Police", 1000, "ar1"), 3000, 1},
+		{"names differ only in case", candidate("Karma Police", 1000, "ar1"), candidate("KARMA POLICE", 1000, "ar1"), 3000, 1},
+		{"a remaster's name is the same once normalized", candidate("Karma Police", 264000, "ar1"), candidate("Karma Police - Remastered 2009", 264000, "ar1"), 3000, 1},
+		{"a hyphenated remaster's name is the same once normalized", candidate("Karma Police", 264000, "ar1"), candidate("Karma Police - 2011 Re-master", 264000, "ar1"), 3000, 1},
+		{"half the tolerance apart", candidate("abcd", 0, "ar1"), candidate("abcd", 1500, "ar1"), 3000, 5.0 / 6},
+		{"exactly the tolerance apart", candidate("abcd", 0, "ar1"), candidate("abcd", 3000, "ar1"), 3000, 2.0 / 3},
+		{"duration score is clamped beyond the tolerance", candidate("abcd", 0, "ar1"), candidate("abcd", 9000, "ar1"), 3000, 2.0 / 3},
+		{"one edit in four characters", candidate("abcd", 0, "ar1"), candidate("abce", 0, "ar1"), 3000, 11.0 / 12},
+		{"completely different names", candidate("abcd", 0, "ar1"), candidate("wxyz", 0, "ar1"), 3000, 2.0 / 3},
+		{"one of two artists shared", candidate("abcd", 0, "ar1"), candidate("abcd", 0, "ar1", "ar2"), 3000, 5.0 / 6},
+		{"no artists shared", candidate("abcd", 0, "ar1"), candidate("abcd", 0, "ar2"), 3000, 2.0 / 3},
+		{"empty names and no artists", candidate("", 0), candidate("", 0), 3000, 1},
+		{"zero tolerance ignores duration", candidate("abcd", 0, "ar1"), candidate("abcd", 5000, "ar1"), 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := similarity(tt.a, tt.b, tt.toleranceMs); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("similarity(%q, %q) = %v, want %v", tt.a.track.Name, tt.b.track.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArtistSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want float64
+	}{
+		{nil, nil, 1},
+		{[]string{"ar1"}, nil, 0},
+		{[]string{"ar1"}, []string{"ar1"}, 1},
+		{[]string{"ar1"}, []string{"ar2"}, 0},
+		{[]string{"ar1", "ar2"}, []string{"ar2"}, 0.5},
+		{[]string{"ar1", "ar2", "ar3"}, []string{"ar2", "ar3", "ar4"}, 0.5},
+	}
+
+	for _, tt := range tests {
+		if got := artistSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("artistSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGroupBySharedArtists(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []similarCandidate
+		want       [][]string
+	}{
+		{"one candidate", []similarCandidate{candidate("a", 0, "ar1")}, [][]string{{"a"}}},
+		{
+			"the same artists",
+			[]similarCandidate{candidate("a - Remastered", 0, "ar1"), candidate("a", 0, "ar1")},
+			[][]string{{"a", "a - Remastered"}},
+		},
+		{
+			"a featured artist",
+			[]similarCandidate{candidate("a (feat. B)", 0, "ar1", "ar2"), candidate("a", 0, "ar1")},
+			[][]string{{"a", "a (feat. B)"}},
+		},
+		{
+			"different artists",
+			[]similarCandidate{candidate("a", 0, "ar1"), candidate("a!", 0, "ar2"), candidate("a!!", 0, "ar1")},
+			[][]string{{"a", "a!!"}, {"a!"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, group := range groupBySharedArtists(tt.candidates) {
+				var names []string
+				for _, c := range group {
+					names = append(names, c.track.Name)
+				}
+
+				got = append(got, names)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupBySharedArtists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
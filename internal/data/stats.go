@@ -0,0 +1,128 @@
+package data
+
+import (
+	"database/sql"
+
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Number of top artists and albums listed by the statistics dashboard and the stats command
+const StatsLimit = 10
+
+// Get aggregate statistics about the DB, listing the top limit artists and albums
+func GetLibraryStats(limit int, repo *db.Repository) (models.LibraryStats, error) {
+	var stats models.LibraryStats
+
+	/*
+		SELECT (SELECT COUNT(*) FROM Playlist),
+		       (SELECT COUNT(*) FROM Track),
+		       (SELECT COUNT(*) FROM Album),
+		       (SELECT COUNT(*) FROM Artist)
+	*/
+	row, err := repo.QueryRow("SELECT (SELECT COUNT(*) FROM Playlist), (SELECT COUNT(*) FROM Track), (SELECT COUNT(*) FROM Album), (SELECT COUNT(*) FROM Artist)")
+
+	if err != nil {
+		return stats, err
+	}
+
+	if err := row.Scan(&stats.Playlists, &stats.Tracks, &stats.Albums, &stats.Artists); err != nil {
+		return stats, err
+	}
+
+	/*
+		SELECT AR.name, COUNT(*) AS tracks
+		FROM TrackArtist TA
+		         JOIN Artist AR ON TA.artist_id = AR.id
+		GROUP BY AR.id
+		ORDER BY tracks DESC, AR.name
+		LIMIT @Limit
+	*/
+	stats.TopArtists, err = queryTallies(
+		"SELECT AR.name, COUNT(*) AS tracks FROM TrackArtist TA JOIN Artist AR ON TA.artist_id = AR.id GROUP BY AR.id ORDER BY tracks DESC, AR.name LIMIT @Limit",
+		repo, sql.Named("Limit", limit))
+
+	if err != nil {
+		return stats, err
+	}
+
+	// albums are named with their artists, since album names such as "Greatest Hits" aren't unique
+	/*
+		SELECT A.name || IFNULL(' (' || (SELECT GROUP_CONCAT(AR.name, '; ')
+		                                 FROM AlbumArtist AA
+		                                          JOIN Artist AR ON AA.artist_id = AR.id
+		                                 WHERE AA.album_id = A.id) || ')', '') AS album,
+		       COUNT(*)                                                           AS appearances
+		FROM PlaylistTrack PT
+		         JOIN Track T ON PT.track_id = T.id
+		         JOIN Album A ON T.album_id = A.id
+		GROUP BY A.id
+		ORDER BY appearances DESC, A.name
+		LIMIT @Limit
+	*/
+	stats.TopAlbums, err = queryTallies(
+		"SELECT A.name || IFNULL(' (' || (SELECT GROUP_CONCAT(AR.name, '; ') FROM AlbumArtist AA JOIN Artist AR ON AA.artist_id = AR.id WHERE AA.album_id = A.id) || ')', '') AS album, COUNT(*) AS appearances FROM PlaylistTrack PT JOIN Track T ON PT.track_id = T.id JOIN Album A ON T.album_id = A.id GROUP BY A.id ORDER BY appearances DESC, A.name LIMIT @Limit",
+		repo, sql.Named("Limit", limit))
+
+	if err != nil {
+		return stats, err
+	}
+
+	// added_at is an ISO 8601 timestamp, so its first 7 characters are the month
+	/*
+		SELECT SUBSTR(added_at, 1, 7) AS month, COUNT(*)
+		FROM PlaylistTrack
+		WHERE added_at IS NOT NULL
+		  AND added_at != ''
+		GROUP BY month
+		ORDER BY month
+	*/
+	stats.TracksAddedPerMonth, err = queryTallies(
+		"SELECT SUBSTR(added_at, 1, 7) AS month, COUNT(*) FROM PlaylistTrack WHERE added_at IS NOT NULL AND added_at != '' GROUP BY month ORDER BY month",
+		repo)
+
+	if err != nil {
+		return stats, err
+	}
+
+	/*
+		SELECT IFNULL(NULLIF(album_type, ''), 'unknown') AS type, COUNT(*) AS albums
+		FROM Album
+		GROUP BY type
+		ORDER BY albums DESC, type
+	*/
+	stats.AlbumTypes, err = queryTallies(
+		"SELECT IFNULL(NULLIF(album_type, ''), 'unknown') AS type, COUNT(*) AS albums FROM Album GROUP BY type ORDER BY albums DESC, type",
+		repo)
+
+	return stats, err
+}
+
+// Run a query whose rows are a name and a count
+func queryTallies(query string, repo *db.Repository, args ...interface{}) ([]models.Tally, error) {
+	rows, err := repo.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tallies []models.Tally
+
+	for rows.Next() {
+		var tally models.Tally
+
+		if err := rows.Scan(&tally.Name, &tally.Count); err != nil {
+			return nil, err
+		}
+
+		tallies = append(tallies, tally)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tallies, nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Repository owns the connection to the Sqlite DB and the statements prepared against it.
+// It is opened once at startup and shared by every query in the data package.
+type Repository struct {
+	conn *sql.DB
+
+	mu         sync.Mutex
+	statements map[string]*sql.Stmt
+}
+
+// Open the Sqlite DB at the file path and verify that it can be reached
+func Open(filePath string) (*Repository, error) {
+	conn, err := sql.Open("sqlite3", filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Repository{conn: conn, statements: make(map[string]*sql.Stmt)}, nil
+}
+
+// Get the prepared statement for the query, preparing it on first use
+func (r *Repository) Prepare(query string) (*sql.Stmt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stmt, ok := r.statements[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := r.conn.Prepare(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.statements[query] = stmt
+
+	return stmt, nil
+}
+
+// Run the query as a prepared statement
+func (r *Repository) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := r.Prepare(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.Query(args...)
+}
+
+// Run the query as a prepared statement, stopping it if the context is cancelled
+func (r *Repository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := r.Prepare(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+// Run the query as a prepared statement, returning at most one row
+func (r *Repository) QueryRow(query string, args ...interface{}) (*sql.Row, error) {
+	stmt, err := r.Prepare(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.QueryRow(args...), nil
+}
+
+// Run the query without preparing it, for queries whose text changes from call to call,
+// e.g. an IN list with a parameter per id, which would otherwise add a statement per text to the cache
+func (r *Repository) QueryUnprepared(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.conn.QueryContext(ctx, query, args...)
+}
+
+// Start a transaction for statements that modify the DB
+func (r *Repository) Begin() (*sql.Tx, error) {
+	return r.conn.Begin()
+}
+
+// Close every prepared statement and then the DB connection
+func (r *Repository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for query, stmt := range r.statements {
+		stmt.Close()
+		delete(r.statements, query)
+	}
+
+	return r.conn.Close()
+}
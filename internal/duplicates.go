@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/rivo/tview"
+)
+
+// Choose which playlists to find duplicate Songs in
+func ShowDuplicateSongOptions(v *models.View) {
+	showDuplicateSongOptions(v, false, false)
+}
+
+// keys of the Duplicate Songs menu, other than its numbered options, for its help
+var duplicateOptionsKeys = []models.ScreenAction{{Action: models.ActionDuplicatesMode}}
+
+// similar is whether to find different tracks that are probably the same recording, rather than repeats of the same track.
+// replace is whether to replace the current screen, which is done when the mode changes.
+func showDuplicateSongOptions(v *models.View, similar bool, replace bool) {
+	v.UpdateTitleBar("Find duplicate Songs")
+
+	mode := "Match: the same track"
+	if similar {
+		mode = "Match: similar tracks, e.g. a single and its remaster"
+	}
+
+	v.List = NewList(v)
+	v.List.
+		AddItem("Playlist Group", "Find duplicates in a group of playlists, e.g. the Starred Playlists", '1', func() {
+			SelectPlaylistGroup(v, "Find duplicate Songs in", func(group models.PlaylistGroup) {
+				playlists, err := data.GetPlaylistGroupPlaylists(group, v.DB)
+
+				if err != nil {
+					v.ShowError(fmt.Errorf("could not load %s Playlists: %w", group.Name, err))
+					return
+				}
+
+				findDuplicateSongs(v, fmt.Sprintf("%s Playlists", group.Name), data.PlaylistsOf(playlists), similar)
+			})
+		}).
+		AddItem("All Playlists", "Find duplicates across every playlist", '2', func() {
+			findDuplicateSongs(v, "all Playlists", data.AllPlaylists, similar)
+		}).
+		AddItem("Chosen Playlists", "Find duplicates across the playlists you pick, or within a single playlist", '3', func() { choosePlaylistsForDuplicates(v, similar) }).
+		AddItem(mode, fmt.Sprintf("Press %s to change", v.Keymap.Label(models.ActionDuplicatesMode)), v.Keymap.Shortcut(models.ActionDuplicatesMode), func() { showDuplicateSongOptions(v, !similar, true) })
+
+	AddQuitToHomeOption(v.List, v)
+
+	v.List.SetTitle("Duplicate Songs").SetBorderColor(v.Theme.MenuBorder)
+
+	if replace {
+		v.ReplaceScreen("duplicate-options", v.List, screenKeys(listKeys, duplicateOptionsKeys, quitToMenuKeys)...)
+		return
+	}
+
+	v.PushScreen("duplicate-options", v.List, screenKeys(listKeys, duplicateOptionsKeys, quitToMenuKeys)...)
+}
+
+// Find duplicate Songs in the playlists, which are described by where, e.g. "all Playlists"
+func findDuplicateSongs(v *models.View, where string, playlists data.PlaylistSet, similar bool) {
+	if similar {
+		groups, err := data.FindSimilarTracks(playlists, data.SimilarDurationTolerance, v.DB)
+		showSimilarSongs(v, where, groups, err)
+		return
+	}
+
+	duplicates, err := data.FindDuplicateTracks(playlists, v.DB)
+	showDuplicateSongs(v, where, duplicates, err)
+}
+
+// Find duplicate Songs in the playlist, i.e. Songs that were added to it more than once
+func showDuplicateSongsInPlaylist(v *models.View, playlist models.SimpleIdentifier) {
+	findDuplicateSongs(v, playlist.Name, data.PlaylistsOf([]models.SimpleIdentifier{playlist}), false)
+}
+
+// Pick playlists from a checklist, then find the duplicate Songs in them
+func choosePlaylistsForDuplicates(v *models.View, similar bool) {
+	v.UpdateTitleBar("Choose Playlists to find duplicate Songs in")
+
+	choosePlaylists(v, models.ActionPlaylistDuplicates, "find duplicates", 1, nil, func(picked []models.SimpleIdentifier) {
+		where := fmt.Sprintf("%d Playlists", len(picked))
+		if len(picked) == 1 {
+			where = picked[0].Name
+		}
+
+		findDuplicateSongs(v, where, data.PlaylistsOf(picked), similar)
+	})
+}
+
+// Display the groups of similar Songs found in the playlists described by where
+func showSimilarSongs(v *models.View, where string, groups []models.SimilarTracks, err error) {
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load similar Songs: %w", err))
+		return
+	}
+
+	v.UpdateTitleBar(fmt.Sprintf("%d groups of similar songs in %s", len(groups), where))
+
+	displaySimilarSongs(v, groups)
+}
+
+// Display each group of similar Songs with a row per track, scored against the group's first track
+func displaySimilarSongs(v *models.View, groups []models.SimilarTracks) {
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("Match").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 1, tview.NewTableCell("Similarity").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+	table.SetCell(0, 2, tview.NewTableCell("Track Name").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 4, tview.NewTableCell("Album Name").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 5, tview.NewTableCell("Length").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+	table.SetCell(0, 6, tview.NewTableCell("Playlists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+
+	// the track displayed in each row, offset for the header row
+	var tracks []models.SimilarTrack
+
+	// set table contents
+	for _, g := range groups {
+		for i, t := range g.Tracks {
+			// use len(tracks)+1 to offset for header row
+			row := len(tracks) + 1
+
+			// only the first track's row names the group
+			if i == 0 {
+				table.SetCell(row, 0, tview.NewTableCell(padLeft(g.Key)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+			}
+
+			table.SetCell(row, 1, tview.NewTableCell(padRight(t.SimilarityPercent())).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(0))
+			table.SetCell(row, 2, tview.NewTableCell(padLeft(t.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(row, 3, tview.NewTableCell(padLeft(t.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(row, 4, tview.NewTableCell(padLeft(t.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(row, 5, tview.NewTableCell(padRight(formatDuration(t.DurationMs))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(0))
+			table.SetCell(row, 6, tview.NewTableCell(padLeft(t.Playlists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+
+			tracks = append(tracks, t)
+		}
+	}
+
+	// open the Song when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectSong(v, tracks[row-1].Id, tracks[row-1].Name)
+		}
+	})
+
+	// the Export action exports the groups, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{name: "similar-songs", columns: models.SimilarTracksColumns(), records: models.SimilarTracksRecords(groups)}
+	}, BackFunc(v)))
+
+	v.PushScreen("similar-songs", table, screenKeys(openRowKeys, exportKeys, backKeys)...)
+}
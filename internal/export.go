@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ccb012100/go-playlist-search/internal/format"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// formats that a displayed table can be exported as, in the order they're offered
+var exportFormats = []format.Format{format.CSV, format.JSON, format.Markdown}
+
+// formats that a displayed list of tracks can additionally be exported as
+var exportPlaylistFormats = []format.Format{format.M3U, format.XSPF}
+
+// The rows of a displayed table, for writing to a file
+type tableExport struct {
+	// prefix of the exported file's name
+	name    string
+	columns []string
+	records []models.Record
+	// the rows as tracks, so the table can be exported as a playlist file; nil if the rows aren't tracks
+	entries []models.PlaylistEntry
+	// title of the exported playlist file
+	title string
+}
+
+// Input capture for a table whose rows can be exported with the Export action's keys;
+// every other key is handled by next.
+// The rows are read when the key is pressed, so they're in their displayed order.
+func exportTableFunc(v *models.View, rows func() tableExport, next func(*tcell.EventKey) *tcell.EventKey) func(*tcell.EventKey) *tcell.EventKey {
+	return func(e *tcell.EventKey) *tcell.EventKey {
+		if v.Keymap.Matches(models.ActionExport, e) {
+			showExportModal(v, rows())
+			return nil
+		}
+
+		return next(e)
+	}
+}
+
+// Ask which format to export the rows as, then return to the current screen
+func showExportModal(v *models.View, export tableExport) {
+	formats := exportFormats
+	if export.entries != nil {
+		formats = append(append([]format.Format{}, exportFormats...), exportPlaylistFormats...)
+	}
+
+	var buttons []string
+	for _, f := range formats {
+		buttons = append(buttons, string(f))
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Export %d rows as:", len(export.records))).
+		AddButtons(append(buttons, "cancel")).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			v.CloseModal()
+
+			// the modal was cancelled, either with the button or Esc
+			if buttonIndex < 0 || buttonIndex >= len(formats) {
+				return
+			}
+
+			path, err := exportRows(v.ExportDir, formats[buttonIndex], export)
+
+			if err != nil {
+				v.ShowError(fmt.Errorf("could not export table: %w", err))
+				return
+			}
+
+			v.UpdateMessageBar(fmt.Sprintf("Exported %d rows to %s", len(export.records), path))
+		})
+
+	v.ShowModal(modal)
+}
+
+// Write the rows to a new timestamped file in the directory, returning the file's path
+func exportRows(dir string, f format.Format, export tableExport) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	file, path, err := createExportFile(dir, fmt.Sprintf("%s-%s", export.name, time.Now().Format("20060102-150405")), f.Extension())
+
+	if err != nil {
+		return "", err
+	}
+
+	if f.IsPlaylist() {
+		err = format.WritePlaylist(file, f, export.title, export.entries)
+	} else {
+		err = format.Write(file, f, export.columns, export.records)
+	}
+
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+
+	return path, file.Close()
+}
+
+// Create a file named for the export that doesn't already exist, so that an export never overwrites another,
+// e.g. "playlist-20220102-150405.csv", then "playlist-20220102-150405-2.csv" for a second export in the same second
+func createExportFile(dir string, name string, extension string) (*os.File, string, error) {
+	for n := 1; ; n++ {
+		fileName := fmt.Sprintf("%s.%s", name, extension)
+		if n > 1 {
+			fileName = fmt.Sprintf("%s-%d.%s", name, n, extension)
+		}
+
+		path, err := filepath.Abs(filepath.Join(dir, fileName))
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+
+		return file, path, err
+	}
+}
@@ -0,0 +1,213 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Format is an output format for query results
+type Format string
+
+const (
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	Table    Format = "table"
+	Markdown Format = "markdown"
+	M3U      Format = "m3u"
+	XSPF     Format = "xspf"
+)
+
+// Every supported Format
+var Formats = []Format{JSON, CSV, TSV, Table, Markdown, M3U, XSPF}
+
+// Get the Format with the name
+func Parse(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown format '%s', expected json, csv, tsv, table, markdown, m3u or xspf", name)
+}
+
+// Write the records in the format.
+// The columns are written as the header row, so that there's a header even if there are no records.
+func Write(w io.Writer, f Format, columns []string, records []models.Record) error {
+	switch f {
+	case JSON:
+		return writeJSON(w, records)
+	case CSV:
+		return writeCSV(w, columns, records)
+	case TSV:
+		return writeTSV(w, columns, records)
+	case Table:
+		return writeTable(w, columns, records)
+	case Markdown:
+		return writeMarkdown(w, columns, records)
+	case M3U, XSPF:
+		return fmt.Errorf("%s can only be written for lists of tracks", f)
+	}
+
+	return fmt.Errorf("unknown format '%s'", f)
+}
+
+// File extension for files written in the format
+func (f Format) Extension() string {
+	switch f {
+	case Table:
+		return "txt"
+	case Markdown:
+		return "md"
+	}
+
+	return string(f)
+}
+
+// Whether the format is a playlist file, which is written with WritePlaylist rather than Write
+func (f Format) IsPlaylist() bool {
+	return f == M3U || f == XSPF
+}
+
+func writeJSON(w io.Writer, records []models.Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	// write an empty array rather than null
+	if records == nil {
+		records = []models.Record{}
+	}
+
+	return encoder.Encode(records)
+}
+
+func writeCSV(w io.Writer, columns []string, records []models.Record) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := writer.Write(r.Values()); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// tabs and newlines can't be escaped in TSV, so they're replaced with spaces
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func writeTSV(w io.Writer, columns []string, records []models.Record) error {
+	writeRow := func(values []string) error {
+		for i, v := range values {
+			values[i] = tsvReplacer.Replace(v)
+		}
+
+		_, err := fmt.Fprintln(w, strings.Join(values, "\t"))
+		return err
+	}
+
+	if err := writeRow(append([]string{}, columns...)); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := writeRow(r.Values()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write the records as aligned columns for reading in a terminal.
+// Columns that are blank in every record are left out.
+func writeTable(w io.Writer, columns []string, records []models.Record) error {
+	rows := make([][]string, len(records))
+	for i, r := range records {
+		rows[i] = r.Values()
+	}
+
+	var keep []int
+	for c := range columns {
+		for _, row := range rows {
+			if row[c] != "" {
+				keep = append(keep, c)
+				break
+			}
+		}
+	}
+
+	// keep every column when there are no records, so the header is still meaningful
+	if len(rows) == 0 {
+		for c := range columns {
+			keep = append(keep, c)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	writeRow := func(values []string) {
+		var kept []string
+		for _, c := range keep {
+			kept = append(kept, values[c])
+		}
+
+		fmt.Fprintln(tw, strings.Join(kept, "\t"))
+	}
+
+	writeRow(columns)
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return tw.Flush()
+}
+
+// pipes would end the cell and newlines the row, so they're escaped
+var markdownReplacer = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+// Write the records as a GitHub-flavored Markdown table
+func writeMarkdown(w io.Writer, columns []string, records []models.Record) error {
+	writeRow := func(values []string) error {
+		for i, v := range values {
+			values[i] = markdownReplacer.Replace(v)
+		}
+
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(values, " | "))
+		return err
+	}
+
+	if err := writeRow(append([]string{}, columns...)); err != nil {
+		return err
+	}
+
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	if err := writeRow(separators); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if err := writeRow(r.Values()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+func TestWrite(t *testing.T) {
+	records := models.SimpleIdentifierRecords([]models.SimpleIdentifier{
+		{Id: "p1", Name: "Fav, 90s"},
+		{Id: "p2", Name: "\"Quoted\"\tand | piped\nacross lines"},
+	})
+
+	tests := []struct {
+		format  Format
+		records []models.Record
+		want    string
+	}{
+		{CSV, records, "Id,Name\np1,\"Fav, 90s\"\np2,\"\"\"Quoted\"\"\tand | piped\nacross lines\"\n"},
+		{CSV, nil, "Id,Name\n"},
+		{TSV, records, "Id\tName\np1\tFav, 90s\np2\t\"Quoted\" and | piped across lines\n"},
+		{Markdown, records, "| Id | Name |\n| --- | --- |\n| p1 | Fav, 90s |\n| p2 | \"Quoted\"\tand \\| piped<br>across lines |\n"},
+		{JSON, nil, "[]\n"},
+		{Table, nil, "Id  Name\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b strings.Builder
+
+			if err := Write(&b, tt.format, models.SimpleIdentifier{}.Columns(), tt.records); err != nil {
+				t.Fatalf("Write() returned error: %v", err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePlaylistFormatsOnlyListTracks(t *testing.T) {
+	for _, f := range []Format{M3U, XSPF} {
+		if err := Write(&strings.Builder{}, f, nil, nil); err == nil {
+			t.Errorf("Write(%s) = nil, want an error", f)
+		}
+	}
+}
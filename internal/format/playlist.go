@@ -0,0 +1,107 @@
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Write the entries as a playlist file titled title
+func WritePlaylist(w io.Writer, f Format, title string, entries []models.PlaylistEntry) error {
+	switch f {
+	case M3U:
+		return writeM3U(w, title, entries)
+	case XSPF:
+		return writeXSPF(w, title, entries)
+	}
+
+	return fmt.Errorf("%s is not a playlist format, expected m3u or xspf", f)
+}
+
+// newlines would start a new directive, so they're replaced with spaces
+var m3uReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// Write the entries as an extended M3U file
+func writeM3U(w io.Writer, title string, entries []models.PlaylistEntry) error {
+	var b strings.Builder
+
+	b.WriteString("#EXTM3U\n")
+
+	if title != "" {
+		fmt.Fprintf(&b, "#PLAYLIST:%s\n", m3uReplacer.Replace(title))
+	}
+
+	for _, e := range entries {
+		// -1 is the conventional length of a track whose duration is unknown
+		seconds := -1
+		if e.DurationMs > 0 {
+			seconds = (e.DurationMs + 500) / 1000
+		}
+
+		fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n", seconds, m3uReplacer.Replace(e.Artist), m3uReplacer.Replace(e.Title))
+
+		if e.Album != "" {
+			fmt.Fprintf(&b, "#EXTALB:%s\n", m3uReplacer.Replace(e.Album))
+		}
+
+		fmt.Fprintln(&b, e.URI())
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// XML elements of an XSPF playlist, see https://xspf.org/spec
+type xspfPlaylist struct {
+	XMLName   xml.Name      `xml:"http://xspf.org/ns/0/ playlist"`
+	Version   int           `xml:"version,attr"`
+	Title     string        `xml:"title,omitempty"`
+	TrackList xspfTrackList `xml:"trackList"`
+}
+
+// trackList is required, even when it's empty
+type xspfTrackList struct {
+	Tracks []xspfTrack `xml:"track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+// Write the entries as an XSPF file
+func writeXSPF(w io.Writer, title string, entries []models.PlaylistEntry) error {
+	playlist := xspfPlaylist{Version: 1, Title: title}
+
+	for _, e := range entries {
+		playlist.TrackList.Tracks = append(playlist.TrackList.Tracks, xspfTrack{
+			Location: e.URI(),
+			Title:    e.Title,
+			Creator:  e.Artist,
+			Album:    e.Album,
+			Duration: e.DurationMs,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(playlist); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w)
+
+	return err
+}
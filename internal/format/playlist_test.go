@@ -0,0 +1,92 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+var testEntries = []models.PlaylistEntry{
+	{Id: "t1", Title: "Airbag", Artist: "Radiohead", Album: "OK Computer", DurationMs: 284499},
+	{Id: "t2", Title: "Sour Times <Live>\nTake 2", Artist: "Portishead; Tom & Jerry", DurationMs: 0},
+}
+
+func TestWritePlaylist(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		title   string
+		entries []models.PlaylistEntry
+		want    string
+	}{
+		{
+			name:    "m3u",
+			format:  M3U,
+			title:   "Road\nTrip",
+			entries: testEntries,
+			want: "#EXTM3U\n" +
+				"#PLAYLIST:Road Trip\n" +
+				"#EXTINF:284,Radiohead - Airbag\n" +
+				"#EXTALB:OK Computer\n" +
+				"spotify:track:t1\n" +
+				"#EXTINF:-1,Portishead; Tom & Jerry - Sour Times <Live> Take 2\n" +
+				"spotify:track:t2\n",
+		},
+		{name: "m3u without a title or entries", format: M3U, want: "#EXTM3U\n"},
+		{
+			name:    "xspf",
+			format:  XSPF,
+			title:   "Rock & Roll",
+			entries: testEntries,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist xmlns="http://xspf.org/ns/0/" version="1">
+  <title>Rock &amp; Roll</title>
+  <trackList>
+    <track>
+      <location>spotify:track:t1</location>
+      <title>Airbag</title>
+      <creator>Radiohead</creator>
+      <album>OK Computer</album>
+      <duration>284499</duration>
+    </track>
+    <track>
+      <location>spotify:track:t2</location>
+      <title>Sour Times &lt;Live&gt;&#xA;Take 2</title>
+      <creator>Portishead; Tom &amp; Jerry</creator>
+    </track>
+  </trackList>
+</playlist>
+`,
+		},
+		{
+			name:   "xspf without a title or entries",
+			format: XSPF,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<playlist xmlns="http://xspf.org/ns/0/" version="1">
+  <trackList></trackList>
+</playlist>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+
+			if err := WritePlaylist(&b, tt.format, tt.title, tt.entries); err != nil {
+				t.Fatalf("WritePlaylist() returned error: %v", err)
+			}
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("WritePlaylist() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePlaylistRejectsOtherFormats(t *testing.T) {
+	if err := WritePlaylist(&strings.Builder{}, CSV, "", testEntries); err == nil {
+		t.Error("WritePlaylist(csv) = nil, want an error")
+	}
+}
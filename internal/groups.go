@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+)
+
+// Pick which playlist group to run f against.
+// If only one group is configured, f is run against it without asking.
+// action describes f, e.g. "Search", and is shown in the picker's title.
+func SelectPlaylistGroup(v *models.View, action string, f func(models.PlaylistGroup)) {
+	groups := v.PlaylistGroups
+	if len(groups) == 0 {
+		groups = []models.PlaylistGroup{models.DefaultPlaylistGroup}
+	}
+
+	if len(groups) == 1 {
+		f(groups[0])
+		return
+	}
+
+	v.UpdateTitleBar(fmt.Sprintf("%s which playlists?", action))
+
+	v.List = NewList(v)
+
+	for i, g := range groups {
+		group := g
+
+		playlists, err := data.GetPlaylistGroupPlaylists(group, v.DB)
+
+		if err != nil {
+			v.ShowError(fmt.Errorf("could not load %s Playlists: %w", group.Name, err))
+			return
+		}
+
+		// only the first 9 groups get a shortcut
+		var shortcut rune
+		if i < 9 {
+			shortcut = rune('1' + i)
+		}
+
+		v.List.AddItem(group.Name, fmt.Sprintf("%d playlists", len(playlists)), shortcut, func() {
+			v.UpdateTitleBar(fmt.Sprintf("%s Playlists", group.Name))
+			f(group)
+		})
+	}
+
+	AddQuitToHomeOption(v.List, v)
+
+	v.List.SetTitle("Playlist Groups").SetBorderColor(v.Theme.MenuBorder)
+
+	v.PushScreen("playlist-groups", v.List, screenKeys(listKeys, quitToMenuKeys)...)
+}
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Keys that screens declare when they're pushed, for their help.
+// A screen combines the groups for the widgets it's made of, e.g. a table that opens rows, exports them and goes back.
+var (
+	// lists made with NewList
+	listKeys = []models.ScreenAction{
+		{Keys: "Enter", Description: "Choose the selected item"},
+		{Keys: "key in brackets", Description: "Choose that item"},
+		{Action: models.ActionListNext},
+		{Action: models.ActionListPrevious},
+	}
+	quitToMenuKeys = []models.ScreenAction{{Action: models.ActionQuit, Description: "Return to the main menu"}}
+	resetKeys      = []models.ScreenAction{{Action: models.ActionReset}}
+
+	// tables whose rows open another screen
+	openRowKeys = []models.ScreenAction{{Keys: "Enter", Description: "Open the selected row"}}
+	// screens handled by BackFunc
+	backKeys   = []models.ScreenAction{{Keys: "Esc", Description: "Go back to the previous screen"}}
+	exportKeys = []models.ScreenAction{{Action: models.ActionExport}}
+
+	// screens that close with Esc, e.g. when there are no results
+	escKeys = []models.ScreenAction{{Keys: "Esc", Description: "Go back to the previous screen"}}
+
+	// search fields whose results are displayed on another screen
+	searchKeys = []models.ScreenAction{
+		{Keys: "Enter", Description: "Search"},
+		{Keys: "Esc", Description: "Go back to the previous screen"},
+	}
+	// search fields laid out with liveSearchLayout, whose results update as you type
+	liveSearchKeys = []models.ScreenAction{
+		{Keys: "Enter", Description: "Show every match"},
+		{Keys: "Esc", Description: "Go back to the previous screen, from the search field"},
+		{Keys: "Tab, Down", Description: "Move from the search field to the matches"},
+		{Keys: "Esc, Backtab", Description: "Move from the matches to the search field"},
+	}
+)
+
+// Join groups of keys into a screen's declaration
+func screenKeys(groups ...[]models.ScreenAction) []models.ScreenAction {
+	var keys []models.ScreenAction
+	for _, g := range groups {
+		keys = append(keys, g...)
+	}
+
+	return keys
+}
+
+// Show the keys that the current screen declared, and the keys that work everywhere, over the screen.
+// Esc, Enter or any character closes it.
+func ShowScreenHelp(v *models.View) {
+	title := "Keys"
+	var keys []models.ScreenAction
+
+	if screen := v.History.Current(); screen != nil {
+		title = screen.Title
+		keys = screen.Actions
+	}
+
+	var global []models.ScreenAction
+	for _, info := range models.Actions {
+		if info.Scope == models.ScopeGlobal {
+			global = append(global, models.ScreenAction{Action: info.Action})
+		}
+	}
+
+	table := newHelpTable(v, fmt.Sprintf("Keys (%s: every key, Esc: close)", v.Keymap.Label(models.ActionHelp)))
+
+	row := addHelpSection(v, table, 0, title, keys)
+	row = addHelpSection(v, table, row+1, string(models.ScopeGlobal), global)
+
+	// 1 row of border above and below
+	v.ShowModal(centered(table, 80, row+2))
+}
+
+// Show every key binding over the current screen, grouped by where they work.
+// Esc, Enter or any character closes it.
+func ShowKeymapHelp(v *models.View) {
+	table := newHelpTable(v, "Every key (Esc to close)")
+
+	row := 0
+	var keys []models.ScreenAction
+
+	for i, info := range models.Actions {
+		keys = append(keys, models.ScreenAction{Action: info.Action})
+
+		// end the group when the scope changes
+		if i+1 < len(models.Actions) && models.Actions[i+1].Scope == info.Scope {
+			continue
+		}
+
+		if row > 0 {
+			row++
+		}
+
+		row = addHelpSection(v, table, row, string(info.Scope), keys)
+		keys = nil
+	}
+
+	v.ShowModal(centered(table, 80, row+2))
+}
+
+// Create a table for a help modal, which closes on Esc, Enter or any character.
+// The arrow and page keys scroll it if it doesn't fit.
+func newHelpTable(v *models.View, title string) *tview.Table {
+	table := newTable(v).SetBorders(false)
+	table.SetBorder(true).SetBorderColor(v.Theme.ListBorder).SetTitle(title)
+
+	table.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+		switch e.Key() {
+		case tcell.KeyESC, tcell.KeyEnter, tcell.KeyRune:
+			v.CloseModal()
+			return nil
+		}
+
+		return e
+	})
+
+	return table
+}
+
+// Add a heading and a row per key from row onwards, returning the row after them
+func addHelpSection(v *models.View, table *tview.Table, row int, heading string, keys []models.ScreenAction) int {
+	table.SetCell(row, 0, tview.NewTableCell(padLeft(heading)).SetTextColor(v.Theme.Header).SetAttributes(tcell.AttrBold))
+	row++
+
+	if len(keys) == 0 {
+		table.SetCell(row, 0, tview.NewTableCell(padLeft("No keys are listed for this screen")).SetTextColor(v.Theme.Muted))
+		return row + 1
+	}
+
+	for _, k := range keys {
+		label, description := k.Keys, k.Description
+
+		if k.Action != "" {
+			label = v.Keymap.Label(k.Action)
+			if label == "" {
+				label = "(unbound)"
+			}
+
+			if info, ok := models.FindAction(string(k.Action)); ok && description == "" {
+				description = info.Description
+			}
+		}
+
+		table.SetCell(row, 0, tview.NewTableCell(padLeft(label)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft))
+		table.SetCell(row, 1, tview.NewTableCell(padLeft(description)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+		row++
+	}
+
+	return row
+}
+
+// Center the Primitive in a box of the given size, with the current screen showing around it.
+// The box shrinks to fit if there isn't room for it.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	rows := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(p, height, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	columns := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(rows, width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	// called before the items are laid out, so they can be resized to fit
+	columns.SetDrawFunc(func(screen tcell.Screen, x, y, w, h int) (int, int, int, int) {
+		columns.ResizeItem(rows, models.Min(width, w), 0)
+		rows.ResizeItem(p, models.Min(height, h), 0)
+
+		return x, y, w, h
+	})
+
+	return columns
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// how long to wait after the last keystroke before searching
+const liveSearchDelay = 300 * time.Millisecond
+
+// shortest query that is searched for as the user types
+const liveSearchMinLength = 2
+
+// Runs a search in the background as the user types into an input field.
+// Each keystroke cancels the previous search, so only the latest query's results are displayed.
+type liveSearch struct {
+	app *tview.Application
+	// runs the query off the UI goroutine, returning the func that displays its results
+	search func(ctx context.Context, query string) func()
+	// empties the results when the query is too short to search for
+	clear func()
+	// cancels the pending or running search;
+	// only called from the UI goroutine, so the results of a cancelled search are never applied
+	cancel context.CancelFunc
+}
+
+func newLiveSearch(app *tview.Application, search func(ctx context.Context, query string) func(), clear func()) *liveSearch {
+	return &liveSearch{app: app, search: search, clear: clear}
+}
+
+// Search for the query once the user stops typing.
+// Must be called from the UI goroutine, e.g. from an InputField's ChangedFunc.
+func (s *liveSearch) Update(query string) {
+	s.Stop()
+
+	if len([]rune(strings.TrimSpace(query))) < liveSearchMinLength {
+		s.clear()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	go func() {
+		// debounce keystrokes
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(liveSearchDelay):
+		}
+
+		display := s.search(ctx, query)
+
+		s.app.QueueUpdateDraw(func() {
+			// a newer query may have been typed while this one was running
+			if ctx.Err() == nil {
+				display()
+			}
+		})
+	}()
+}
+
+// Cancel the pending or running search, if there is one
+func (s *liveSearch) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// Lay out the input field above its live results.
+// Tab or Down moves focus from the input field to the results.
+func liveSearchLayout(v *models.View, input *tview.InputField, results tview.Primitive) *tview.Flex {
+	input.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+		switch e.Key() {
+		case tcell.KeyTab, tcell.KeyDown:
+			v.App.SetFocus(results)
+			return nil
+		}
+
+		return e
+	})
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(results, 0, 1, false)
+}
+
+// Move focus from the live results back to the input field on Esc or Backtab
+func backToInputFunc(v *models.View, input *tview.InputField) func(*tcell.EventKey) *tcell.EventKey {
+	return func(e *tcell.EventKey) *tcell.EventKey {
+		switch e.Key() {
+		case tcell.KeyESC, tcell.KeyBacktab:
+			v.App.SetFocus(input)
+			return nil
+		}
+
+		return e
+	}
+}
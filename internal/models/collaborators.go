@@ -0,0 +1,29 @@
+package models
+
+import "strconv"
+
+// An artist credited on the same tracks as another artist
+type Collaborator struct {
+	Artist SimpleIdentifier `json:"artist"`
+	// number of tracks crediting both artists
+	SharedTracks int `json:"shared_tracks"`
+	// names of the shared tracks, separated by "; "
+	Tracks string `json:"tracks"`
+}
+
+func (c Collaborator) Columns() []string {
+	return []string{"Artist Id", "Artist", "Shared Tracks", "Tracks"}
+}
+
+func (c Collaborator) Values() []string {
+	return []string{c.Artist.Id, c.Artist.Name, strconv.Itoa(c.SharedTracks), c.Tracks}
+}
+
+func CollaboratorRecords(collaborators []Collaborator) []Record {
+	records := make([]Record, len(collaborators))
+	for i, c := range collaborators {
+		records[i] = c
+	}
+
+	return records
+}
@@ -0,0 +1,72 @@
+package models
+
+import "fmt"
+
+// How the tracks of two or more playlists overlap
+type PlaylistComparison struct {
+	Playlists []SimpleIdentifier `json:"playlists"`
+	// tracks in every playlist
+	Common []Track `json:"common"`
+	// tracks in more than one playlist but not all of them, which only happens when comparing 3 or more
+	Shared []Track `json:"shared"`
+	// tracks in only one playlist, indexed like Playlists
+	Unique [][]Track `json:"unique"`
+	// tracks in every playlist as a fraction of the tracks in any of them (the Jaccard index), from 0 to 1
+	Overlap float64 `json:"overlap"`
+}
+
+// Number of different tracks in any of the playlists
+func (c PlaylistComparison) TotalTracks() int {
+	total := len(c.Common) + len(c.Shared)
+	for _, u := range c.Unique {
+		total += len(u)
+	}
+
+	return total
+}
+
+// Summarize the overlap, e.g. "37% overlap: 3 of 8 tracks are in every playlist"
+func (c PlaylistComparison) Summary() string {
+	return fmt.Sprintf("%.0f%% overlap: %d of %d tracks are in every playlist", c.Overlap*100, len(c.Common), c.TotalTracks())
+}
+
+// A track in a PlaylistComparison and which of the compared playlists it's in
+type comparedTrackRecord struct {
+	// e.g. "all", "some" or the name of the only playlist containing the track
+	In string `json:"in"`
+	Track
+}
+
+func (r comparedTrackRecord) Columns() []string {
+	return []string{"In", "Id", "Track", "Artists", "Album", "Playlists"}
+}
+
+func (r comparedTrackRecord) Values() []string {
+	return []string{r.In, r.Id, r.Name, r.Artists, r.AlbumName, r.Playlists}
+}
+
+// Columns of the Records returned by PlaylistComparison.Records
+func PlaylistComparisonColumns() []string {
+	return comparedTrackRecord{}.Columns()
+}
+
+// A Record per track, in the order common, shared, then unique to each playlist
+func (c PlaylistComparison) Records() []Record {
+	var records []Record
+
+	for _, t := range c.Common {
+		records = append(records, comparedTrackRecord{In: "all", Track: t})
+	}
+
+	for _, t := range c.Shared {
+		records = append(records, comparedTrackRecord{In: "some", Track: t})
+	}
+
+	for i, tracks := range c.Unique {
+		for _, t := range tracks {
+			records = append(records, comparedTrackRecord{In: c.Playlists[i].Name, Track: t})
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,43 @@
+package models
+
+// PlaylistEntry is a track written to a playlist file, e.g. an M3U file
+type PlaylistEntry struct {
+	Id         string
+	Title      string
+	Artist     string
+	Album      string
+	DurationMs int
+}
+
+// Spotify URI of the track, which players use to locate it
+func (e PlaylistEntry) URI() string {
+	return "spotify:track:" + e.Id
+}
+
+func (t Track) PlaylistEntry() PlaylistEntry {
+	return PlaylistEntry{Id: t.Id, Title: t.Name, Artist: t.Artists, Album: t.AlbumName, DurationMs: t.DurationMs}
+}
+
+func (m StarredPlaylistMatch) PlaylistEntry() PlaylistEntry {
+	return PlaylistEntry{Id: m.TrackId, Title: m.TrackName, Artist: m.Artists, Album: m.AlbumName, DurationMs: m.DurationMs}
+}
+
+// The functions below convert slices of results into PlaylistEntries
+
+func TrackEntries(tracks []Track) []PlaylistEntry {
+	entries := make([]PlaylistEntry, len(tracks))
+	for i, t := range tracks {
+		entries[i] = t.PlaylistEntry()
+	}
+
+	return entries
+}
+
+func StarredPlaylistMatchEntries(matches []StarredPlaylistMatch) []PlaylistEntry {
+	entries := make([]PlaylistEntry, len(matches))
+	for i, m := range matches {
+		entries[i] = m.PlaylistEntry()
+	}
+
+	return entries
+}
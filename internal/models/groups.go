@@ -0,0 +1,63 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A named group of playlists, e.g. the Starred playlists, that searches can be restricted to.
+// A playlist is in the group if its name matches any of the patterns or its id is listed.
+type PlaylistGroup struct {
+	Name string
+	// matched against playlist names
+	Patterns    []*regexp.Regexp
+	PlaylistIds []string
+}
+
+// A playlist in a PlaylistGroup
+type PlaylistGroupMember struct {
+	Group        string `json:"group"`
+	PlaylistId   string `json:"playlist_id"`
+	PlaylistName string `json:"playlist_name"`
+}
+
+// The group used when none are configured
+var DefaultPlaylistGroup = PlaylistGroup{Name: "Starred", Patterns: []*regexp.Regexp{LikePattern("Starred%")}}
+
+// Whether the playlist is in the group
+func (g PlaylistGroup) Contains(playlist SimpleIdentifier) bool {
+	for _, id := range g.PlaylistIds {
+		if id == playlist.Id {
+			return true
+		}
+	}
+
+	for _, p := range g.Patterns {
+		if p.MatchString(playlist.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Convert a SQL LIKE pattern into the equivalent regexp:
+// '%' matches any run of characters, '_' matches a single character and the match is case-insensitive.
+func LikePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,374 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// most screens kept in the History; the oldest are dropped to bound memory over a long session
+const maxScreens = 50
+
+// most screens shown in the TitleBar's breadcrumb; older ones are elided
+const maxBreadcrumbs = 5
+
+// name of the page that ShowModal displays over the current screen
+const modalPage = "modal"
+
+// A screen that has been displayed in the main panel, kept so that it can be returned to
+type Screen struct {
+	// what kind of screen it is, e.g. "artist-tracks"
+	Name string
+	// name of the screen in the TitleBar's breadcrumb
+	Title string
+	Panel tview.Primitive
+	// what the user had done on the screen when they left it
+	State ScreenState
+	// keys that can be used on the screen, for its help
+	Actions []ScreenAction
+	// name of the screen's page in the View's Pages, which is unique
+	page string
+	// the Primitive within the Panel that had focus when the screen was left
+	focus tview.Primitive
+}
+
+// A key that a screen handles, for its help.
+// Either Action names a configurable key, or Keys describes keys that can't be configured, e.g. "Enter".
+type ScreenAction struct {
+	Action Action
+	Keys   string
+	// what the key does on the screen; defaults to the Action's Description
+	Description string
+}
+
+// The parts of a screen that are restored when going back to it
+type ScreenState struct {
+	// text of the screen's search input, if it has one
+	Query string
+	// selected row and column of the focused table or list
+	Row    int
+	Column int
+	// scroll position of the focused table, list or text view
+	RowOffset    int
+	ColumnOffset int
+}
+
+// The screens that have been displayed, oldest first.
+// Each screen is a page of the View's Pages, which only holds the screens in the History.
+type History struct {
+	screens []*Screen
+	// index of the displayed screen; the screens after it can be returned to with Forward
+	current int
+	// title of the next screen to be displayed
+	title string
+	// Primitive displayed with ShowModal over the current screen, if there is one
+	modal tview.Primitive
+	// number of screens ever added, for naming their pages
+	added int
+}
+
+func NewHistory() *History {
+	return &History{current: -1}
+}
+
+// The displayed screen, or nil if nothing has been displayed yet
+func (h *History) Current() *Screen {
+	if h.current < 0 {
+		return nil
+	}
+
+	return h.screens[h.current]
+}
+
+// Titles of the screens up to and including the displayed one
+func (h *History) Titles() []string {
+	titles := make([]string, h.current+1)
+	for i := range titles {
+		titles[i] = h.screens[i].Title
+	}
+
+	return titles
+}
+
+// Set the title of the next screen displayed with PushScreen or ReplaceScreen,
+// and show it at the end of the breadcrumb
+func (v View) UpdateTitleBar(message string) {
+	v.History.title = message
+	v.showBreadcrumb(append(v.History.Titles(), message))
+}
+
+// Rename the displayed screen, e.g. when a live search's query changes
+func (v View) SetScreenTitle(title string) {
+	if screen := v.History.Current(); screen != nil {
+		screen.Title = title
+	}
+
+	v.History.title = title
+	v.showBreadcrumb(v.History.Titles())
+}
+
+// Display the Primitive as a new screen after the current one,
+// titled with the last UpdateTitleBar message, whose help lists the actions.
+// Screens that were gone back from can no longer be returned to with Forward.
+func (v View) PushScreen(name string, p tview.Primitive, actions ...ScreenAction) {
+	h := v.History
+
+	if screen := h.Current(); screen != nil {
+		v.leave(screen)
+	}
+
+	v.removeScreens(h.current + 1)
+
+	// drop the oldest screens rather than keeping every screen of a long session
+	if len(h.screens) >= maxScreens {
+		v.dropOldestScreens(len(h.screens) - maxScreens + 1)
+	}
+
+	v.addScreen(name, p, actions)
+}
+
+// Display the Primitive in place of the current screen, e.g. when the screen's options change,
+// titled with the last UpdateTitleBar message, whose help lists the actions
+func (v View) ReplaceScreen(name string, p tview.Primitive, actions ...ScreenAction) {
+	v.closeModal()
+	v.removeScreens(v.History.current)
+	v.addScreen(name, p, actions)
+}
+
+// Close the current screen and return to the previous one, as it was left.
+// Unlike Back, the closed screen can't be returned to with Forward.
+// Returns false if there is no previous screen.
+func (v View) PopScreen() bool {
+	h := v.History
+
+	if h.current < 1 {
+		return false
+	}
+
+	v.closeModal()
+	v.removeScreens(h.current)
+	v.display(h.Current())
+
+	return true
+}
+
+// Return to the previous screen, as it was left.
+// Returns false if there is no previous screen.
+func (v View) Back() bool {
+	return v.moveTo(v.History.current - 1)
+}
+
+// Return to the screen that Back left.
+// Returns false if there is no such screen.
+func (v View) Forward() bool {
+	return v.moveTo(v.History.current + 1)
+}
+
+// Forget every screen, e.g. before displaying the main menu
+func (v View) ClearHistory() {
+	v.closeModal()
+	v.removeScreens(0)
+}
+
+// Display the Primitive over the current screen without adding it to the History,
+// e.g. for a modal dialog. CloseModal returns to the screen.
+func (v View) ShowModal(p tview.Primitive) {
+	if screen := v.History.Current(); screen != nil {
+		v.leave(screen)
+	}
+
+	v.History.modal = p
+	v.Pages.AddPage(modalPage, p, true, true)
+	v.App.SetFocus(p)
+}
+
+// Return from a Primitive displayed with ShowModal to the current screen
+func (v View) CloseModal() {
+	v.closeModal()
+
+	if screen := v.History.Current(); screen != nil {
+		v.restore(screen)
+	}
+}
+
+// The Primitive displayed with ShowModal, or nil if there isn't one
+func (v View) Modal() tview.Primitive {
+	return v.History.modal
+}
+
+// Remove the modal's page, if there is one
+func (v View) closeModal() {
+	if v.History.modal != nil {
+		v.Pages.RemovePage(modalPage)
+		v.History.modal = nil
+	}
+}
+
+// Add a screen after the current one and display it
+func (v View) addScreen(name string, p tview.Primitive, actions []ScreenAction) {
+	h := v.History
+
+	screen := &Screen{Name: name, Title: h.title, Panel: p, Actions: actions, page: fmt.Sprintf("%d:%s", h.added, name)}
+	h.added++
+
+	h.screens = append(h.screens, screen)
+	h.current = len(h.screens) - 1
+
+	v.Pages.AddPage(screen.page, p, true, false)
+	v.display(screen)
+}
+
+// Remove the screens from index onwards, along with their pages
+func (v View) removeScreens(index int) {
+	h := v.History
+
+	if index < 0 {
+		index = 0
+	}
+
+	for _, screen := range h.screens[index:] {
+		v.Pages.RemovePage(screen.page)
+	}
+
+	h.screens = h.screens[:index]
+
+	if h.current >= len(h.screens) {
+		h.current = len(h.screens) - 1
+	}
+}
+
+// Remove the first n screens, along with their pages
+func (v View) dropOldestScreens(n int) {
+	h := v.History
+
+	for _, screen := range h.screens[:n] {
+		v.Pages.RemovePage(screen.page)
+	}
+
+	h.screens = append([]*Screen{}, h.screens[n:]...)
+	h.current -= n
+}
+
+func (v View) moveTo(index int) bool {
+	h := v.History
+
+	if index < 0 || index >= len(h.screens) {
+		return false
+	}
+
+	v.leave(h.screens[h.current])
+	h.current = index
+
+	v.display(h.screens[index])
+
+	return true
+}
+
+// Show the screen as it was left, with its title in the breadcrumb
+func (v View) display(screen *Screen) {
+	v.History.title = screen.Title
+
+	v.restore(screen)
+	v.showBreadcrumb(v.History.Titles())
+}
+
+// Save the screen before leaving it,
+// unless that was already done when a modal was displayed over it
+func (v View) leave(screen *Screen) {
+	if v.History.modal != nil {
+		v.closeModal()
+		return
+	}
+
+	v.save(screen)
+}
+
+// Record which Primitive has focus and what has been done on the screen
+func (v View) save(screen *Screen) {
+	screen.focus = v.App.GetFocus()
+	screen.State = ScreenState{}
+
+	if input := findInputField(screen.Panel); input != nil {
+		screen.State.Query = input.GetText()
+	}
+
+	switch p := screen.focus.(type) {
+	case *tview.Table:
+		screen.State.Row, screen.State.Column = p.GetSelection()
+		screen.State.RowOffset, screen.State.ColumnOffset = p.GetOffset()
+	case *tview.List:
+		screen.State.Row = p.GetCurrentItem()
+		screen.State.RowOffset, screen.State.ColumnOffset = p.GetOffset()
+	case *tview.TextView:
+		screen.State.RowOffset, screen.State.ColumnOffset = p.GetScrollOffset()
+	}
+}
+
+// Switch to the screen's page as it was when save was last called on it
+func (v View) restore(screen *Screen) {
+	// setting the same text would rerun a live search for no reason
+	if input := findInputField(screen.Panel); input != nil && input.GetText() != screen.State.Query {
+		input.SetText(screen.State.Query)
+	}
+
+	switch p := screen.focus.(type) {
+	case *tview.Table:
+		p.Select(screen.State.Row, screen.State.Column).SetOffset(screen.State.RowOffset, screen.State.ColumnOffset)
+	case *tview.List:
+		p.SetCurrentItem(screen.State.Row).SetOffset(screen.State.RowOffset, screen.State.ColumnOffset)
+	case *tview.TextView:
+		p.ScrollTo(screen.State.RowOffset, screen.State.ColumnOffset)
+	}
+
+	focus := screen.focus
+	if focus == nil {
+		focus = screen.Panel
+	}
+
+	v.Pages.SwitchToPage(screen.page)
+	v.App.SetFocus(focus)
+}
+
+// Show the titles in the TitleBar, eliding the oldest if there are too many
+func (v View) showBreadcrumb(titles []string) {
+	if len(titles) == 0 {
+		v.TitleBar.SetText("")
+		return
+	}
+
+	crumbs := make([]string, 0, maxBreadcrumbs+1)
+	if len(titles) > maxBreadcrumbs {
+		crumbs = append(crumbs, "…")
+		titles = titles[len(titles)-maxBreadcrumbs:]
+	}
+
+	for _, t := range titles[:len(titles)-1] {
+		crumbs = append(crumbs, tview.Escape(t))
+	}
+
+	last := "[::b]" + tview.Escape(titles[len(titles)-1]) + "[::-]"
+
+	if len(crumbs) == 0 {
+		v.TitleBar.SetText(last)
+		return
+	}
+
+	v.TitleBar.SetText("[" + ColorTag(v.Theme.Muted) + "]" + strings.Join(crumbs, " › ") + " › [-]" + last)
+}
+
+// The search input in the Primitive, if it has one
+func findInputField(p tview.Primitive) *tview.InputField {
+	switch p := p.(type) {
+	case *tview.InputField:
+		return p
+	case *tview.Flex:
+		for i := 0; i < p.GetItemCount(); i++ {
+			if input := findInputField(p.GetItem(i)); input != nil {
+				return input
+			}
+		}
+	}
+
+	return nil
+}
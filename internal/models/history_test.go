@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func newTestView() *View {
+	return &View{
+		App:      tview.NewApplication(),
+		Pages:    tview.NewPages(),
+		TitleBar: tview.NewTextView(),
+		History:  NewHistory(),
+	}
+}
+
+// Names of the screens in the History, with the current screen in brackets, e.g. "a [b] c"
+func historyString(h *History) string {
+	names := make([]string, len(h.screens))
+	for i, s := range h.screens {
+		names[i] = s.Name
+		if i == h.current {
+			names[i] = "[" + s.Name + "]"
+		}
+	}
+
+	return strings.Join(names, " ")
+}
+
+// A call on the View: "push <name>", "replace <name>", "pop", "back", "forward" or "clear"
+type historyStep struct {
+	call string
+	// what the call returns, for pop, back and forward
+	want bool
+}
+
+func runHistoryStep(t *testing.T, v *View, step historyStep) {
+	t.Helper()
+
+	fields := strings.Fields(step.call)
+
+	var got bool
+	switch fields[0] {
+	case "push":
+		v.UpdateTitleBar(fields[1])
+		v.PushScreen(fields[1], tview.NewBox())
+		return
+	case "replace":
+		v.UpdateTitleBar(fields[1])
+		v.ReplaceScreen(fields[1], tview.NewBox())
+		return
+	case "clear":
+		v.ClearHistory()
+		return
+	case "pop":
+		got = v.PopScreen()
+	case "back":
+		got = v.Back()
+	case "forward":
+		got = v.Forward()
+	default:
+		t.Fatalf("unknown step %q", step.call)
+	}
+
+	if got != step.want {
+		t.Errorf("%s returned %v, want %v", step.call, got, step.want)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []historyStep
+		want  string
+	}{
+		{"empty", nil, ""},
+		{"push", []historyStep{{call: "push a"}, {call: "push b"}}, "a [b]"},
+		{"back", []historyStep{{call: "push a"}, {call: "push b"}, {call: "back", want: true}}, "[a] b"},
+		{"back from the first screen", []historyStep{{call: "push a"}, {call: "back", want: false}}, "[a]"},
+		{"back with nothing displayed", []historyStep{{call: "back", want: false}}, ""},
+		{"forward", []historyStep{{call: "push a"}, {call: "push b"}, {call: "back", want: true}, {call: "forward", want: true}}, "a [b]"},
+		{"forward from the last screen", []historyStep{{call: "push a"}, {call: "push b"}, {call: "forward", want: false}}, "a [b]"},
+		{"forward with nothing displayed", []historyStep{{call: "forward", want: false}}, ""},
+		{
+			"push after back drops the screens gone back from",
+			[]historyStep{{call: "push a"}, {call: "push b"}, {call: "push c"}, {call: "back", want: true}, {call: "back", want: true}, {call: "push d"}, {call: "forward", want: false}},
+			"a [d]",
+		},
+		{"replace", []historyStep{{call: "push a"}, {call: "push b"}, {call: "replace c"}}, "a [c]"},
+		{
+			"replace after back drops the screens gone back from",
+			[]historyStep{{call: "push a"}, {call: "push b"}, {call: "back", want: true}, {call: "replace c"}},
+			"[c]",
+		},
+		{"replace with nothing displayed", []historyStep{{call: "replace a"}}, "[a]"},
+		{"pop", []historyStep{{call: "push a"}, {call: "push b"}, {call: "pop", want: true}}, "[a]"},
+		{"pop can't be gone forward from", []historyStep{{call: "push a"}, {call: "push b"}, {call: "pop", want: true}, {call: "forward", want: false}}, "[a]"},
+		{"pop the first screen", []historyStep{{call: "push a"}, {call: "pop", want: false}}, "[a]"},
+		{"pop with nothing displayed", []historyStep{{call: "pop", want: false}}, ""},
+		{
+			"pop after back drops the screens gone back from",
+			[]historyStep{{call: "push a"}, {call: "push b"}, {call: "push c"}, {call: "back", want: true}, {call: "pop", want: true}},
+			"[a]",
+		},
+		{"clear", []historyStep{{call: "push a"}, {call: "push b"}, {call: "clear"}}, ""},
+		{"push after clear", []historyStep{{call: "push a"}, {call: "clear"}, {call: "push b"}, {call: "back", want: false}}, "[b]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestView()
+
+			for _, step := range tt.steps {
+				runHistoryStep(t, v, step)
+			}
+
+			if got := historyString(v.History); got != tt.want {
+				t.Errorf("History = %q, want %q", got, tt.want)
+			}
+
+			// the Pages only hold the screens in the History
+			if got, want := v.Pages.GetPageCount(), len(v.History.screens); got != want {
+				t.Errorf("Pages has %d pages, want %d", got, want)
+			}
+
+			if screen := v.History.Current(); screen != nil {
+				if name, _ := v.Pages.GetFrontPage(); name != screen.page {
+					t.Errorf("front page = %q, want the current screen's %q", name, screen.page)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryDropsOldestScreens(t *testing.T) {
+	tests := []struct {
+		pushes    int
+		wantFirst string
+		wantLen   int
+	}{
+		{pushes: maxScreens - 1, wantFirst: "s0", wantLen: maxScreens - 1},
+		{pushes: maxScreens, wantFirst: "s0", wantLen: maxScreens},
+		{pushes: maxScreens + 1, wantFirst: "s1", wantLen: maxScreens},
+		{pushes: maxScreens * 2, wantFirst: fmt.Sprintf("s%d", maxScreens), wantLen: maxScreens},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.pushes), func(t *testing.T) {
+			v := newTestView()
+
+			for i := 0; i < tt.pushes; i++ {
+				runHistoryStep(t, v, historyStep{call: fmt.Sprintf("push s%d", i)})
+			}
+
+			h := v.History
+
+			if len(h.screens) != tt.wantLen || h.screens[0].Name != tt.wantFirst {
+				t.Fatalf("History has %d screens starting at %s, want %d starting at %s", len(h.screens), h.screens[0].Name, tt.wantLen, tt.wantFirst)
+			}
+
+			if want := fmt.Sprintf("s%d", tt.pushes-1); h.Current().Name != want {
+				t.Errorf("current screen = %s, want %s", h.Current().Name, want)
+			}
+
+			if got := v.Pages.GetPageCount(); got != tt.wantLen {
+				t.Errorf("Pages has %d pages, want %d", got, tt.wantLen)
+			}
+
+			// going back stops at the oldest screen that was kept
+			backs := 0
+			for v.Back() {
+				backs++
+			}
+
+			if backs != tt.wantLen-1 || h.Current().Name != tt.wantFirst {
+				t.Errorf("went back %d times to %s, want %d times to %s", backs, h.Current().Name, tt.wantLen-1, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestHistoryTitles(t *testing.T) {
+	v := newTestView()
+
+	for _, step := range []historyStep{{call: "push a"}, {call: "push b"}, {call: "push c"}, {call: "back", want: true}} {
+		runHistoryStep(t, v, step)
+	}
+
+	if got, want := v.History.Titles(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Titles() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,363 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Something the user can do with a key, named as in the KEY_<ACTION> config keys, e.g. "menu_albums"
+type Action string
+
+const (
+	ActionBack    Action = "back"
+	ActionForward Action = "forward"
+	ActionHelp    Action = "help"
+
+	ActionListNext     Action = "list_next"
+	ActionListPrevious Action = "list_previous"
+	ActionQuit         Action = "quit"
+	ActionReset        Action = "reset"
+
+	ActionMenuPlaylists  Action = "menu_playlists"
+	ActionMenuArtists    Action = "menu_artists"
+	ActionMenuAlbums     Action = "menu_albums"
+	ActionMenuSongs      Action = "menu_songs"
+	ActionMenuStarred    Action = "menu_starred"
+	ActionMenuDuplicates Action = "menu_duplicates"
+	ActionMenuEverything Action = "menu_everything"
+	ActionMenuStats      Action = "menu_stats"
+
+	ActionDuplicatesMode Action = "duplicates_mode"
+
+	ActionExport             Action = "export"
+	ActionSort               Action = "sort"
+	ActionPlaylistDuplicates Action = "playlist_duplicates"
+	ActionPlaylistCompare    Action = "playlist_compare"
+)
+
+// Where an Action's keys are handled.
+// Keys can only conflict if their scopes overlap.
+type Scope string
+
+const (
+	// every screen
+	ScopeGlobal Scope = "Everywhere"
+	// every list of options, including the main menu
+	ScopeList             Scope = "Lists"
+	ScopeMainMenu         Scope = "Main Menu"
+	ScopeDuplicateOptions Scope = "Duplicate Songs"
+	// tables of results
+	ScopeTable Scope = "Tables"
+)
+
+// Whether a key can be handled in both scopes
+func (s Scope) Overlaps(other Scope) bool {
+	switch {
+	case s == other, s == ScopeGlobal, other == ScopeGlobal:
+		return true
+	// the main menu and duplicate song options are lists
+	case s == ScopeList:
+		return other == ScopeMainMenu || other == ScopeDuplicateOptions
+	case other == ScopeList:
+		return s == ScopeMainMenu || s == ScopeDuplicateOptions
+	}
+
+	return false
+}
+
+// An Action, where it's handled and its default keys
+type ActionInfo struct {
+	Action      Action
+	Scope       Scope
+	Description string
+	// keys in the format that ParseKey reads
+	Defaults []string
+}
+
+// Whether the Action's keys are List item shortcuts, which can only be plain characters
+func (a ActionInfo) isShortcut() bool {
+	switch a.Scope {
+	case ScopeMainMenu, ScopeDuplicateOptions:
+		return true
+	}
+
+	return a.Action == ActionQuit || a.Action == ActionReset
+}
+
+// Every Action, in the order they're listed in the help
+var Actions = []ActionInfo{
+	{ActionHelp, ScopeGlobal, "Show the screen's keys, then every key", []string{"?", "f1"}},
+	{ActionBack, ScopeGlobal, "Go back to the previous screen", []string{"alt+left", "alt+b"}},
+	{ActionForward, ScopeGlobal, "Go forward to the screen you went back from", []string{"alt+right", "alt+f"}},
+
+	{ActionListNext, ScopeList, "Select the next item", []string{"n"}},
+	{ActionListPrevious, ScopeList, "Select the previous item", []string{"p"}},
+	{ActionQuit, ScopeList, "Quit, or return to the main menu", []string{"q"}},
+	{ActionReset, ScopeList, "Start the search again", []string{"r"}},
+
+	{ActionMenuPlaylists, ScopeMainMenu, "Search Playlists", []string{"a"}},
+	{ActionMenuArtists, ScopeMainMenu, "Search Artists", []string{"s"}},
+	{ActionMenuAlbums, ScopeMainMenu, "Search Albums", []string{"d"}},
+	{ActionMenuSongs, ScopeMainMenu, "Search Songs", []string{"f"}},
+	{ActionMenuStarred, ScopeMainMenu, "Search Starred Playlists", []string{"j"}},
+	{ActionMenuDuplicates, ScopeMainMenu, "Find duplicate Songs", []string{"k"}},
+	{ActionMenuEverything, ScopeMainMenu, "Search everything", []string{"l"}},
+	{ActionMenuStats, ScopeMainMenu, "Show Library Statistics", []string{"g"}},
+
+	{ActionDuplicatesMode, ScopeDuplicateOptions, "Switch between matching the same and similar tracks", []string{"m"}},
+
+	{ActionExport, ScopeTable, "Export the rows to a file", []string{"e"}},
+	{ActionSort, ScopeTable, "Change the sort order", []string{"s"}},
+	{ActionPlaylistDuplicates, ScopeTable, "Find duplicate Songs in the Playlist", []string{"d"}},
+	{ActionPlaylistCompare, ScopeTable, "Compare the Playlist with other Playlists", []string{"c"}},
+}
+
+// keys that lists use for their numbered options, so they can't be bound to list actions
+const numberedOptionKeys = "123456789"
+
+// A key press, optionally with Alt held
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+	Alt  bool
+}
+
+// names of the special keys that ParseKey accepts
+var keyNames = map[string]tcell.Key{
+	"enter":     tcell.KeyEnter,
+	"esc":       tcell.KeyEscape,
+	"tab":       tcell.KeyTab,
+	"backtab":   tcell.KeyBacktab,
+	"backspace": tcell.KeyBackspace2,
+	"delete":    tcell.KeyDelete,
+	"insert":    tcell.KeyInsert,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+	"home":      tcell.KeyHome,
+	"end":       tcell.KeyEnd,
+	"pgup":      tcell.KeyPgUp,
+	"pgdn":      tcell.KeyPgDn,
+	"space":     tcell.KeyRune,
+}
+
+// Parse a key such as "a", "?", "esc", "f1", "ctrl+b", "alt+left" or "alt+b".
+// Single characters are case-sensitive; names and modifiers aren't.
+func ParseKey(spec string) (Key, error) {
+	var key Key
+	name := spec
+
+	for {
+		lower := strings.ToLower(name)
+
+		if strings.HasPrefix(lower, "alt+") && len(name) > len("alt+") {
+			key.Alt = true
+			name = name[len("alt+"):]
+			continue
+		}
+
+		if strings.HasPrefix(lower, "ctrl+") && len(name) > len("ctrl+") {
+			letter := strings.ToLower(name[len("ctrl+"):])
+			if len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+				return key, fmt.Errorf("invalid key '%s': ctrl can only be combined with a letter", spec)
+			}
+
+			key.Key = tcell.KeyCtrlA + tcell.Key(letter[0]-'a')
+			return key, nil
+		}
+
+		break
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		key.Key = tcell.KeyRune
+		key.Rune, _ = utf8.DecodeRuneInString(name)
+		return key, nil
+	}
+
+	lower := strings.ToLower(name)
+
+	if k, ok := keyNames[lower]; ok {
+		key.Key = k
+		if lower == "space" {
+			key.Rune = ' '
+		}
+
+		return key, nil
+	}
+
+	if strings.HasPrefix(lower, "f") {
+		if f, err := strconv.Atoi(lower[1:]); err == nil && f >= 1 && f <= 12 {
+			key.Key = tcell.KeyF1 + tcell.Key(f-1)
+			return key, nil
+		}
+	}
+
+	return key, fmt.Errorf("invalid key '%s'", spec)
+}
+
+// The key as ParseKey reads it, e.g. "alt+left"
+func (k Key) String() string {
+	var name string
+
+	switch {
+	case k.Key == tcell.KeyRune && k.Rune == ' ':
+		name = "space"
+	case k.Key == tcell.KeyRune:
+		name = string(k.Rune)
+	case k.Key >= tcell.KeyF1 && k.Key <= tcell.KeyF12:
+		name = fmt.Sprintf("f%d", k.Key-tcell.KeyF1+1)
+	default:
+		// checked before the ctrl keys, since e.g. Tab is the same key as Ctrl+I
+		for n, key := range keyNames {
+			if key == k.Key && n != "space" {
+				name = n
+			}
+		}
+
+		if name == "" && k.Key >= tcell.KeyCtrlA && k.Key <= tcell.KeyCtrlZ {
+			name = fmt.Sprintf("ctrl+%c", 'a'+rune(k.Key-tcell.KeyCtrlA))
+		}
+	}
+
+	if k.Alt {
+		return "alt+" + name
+	}
+
+	return name
+}
+
+// Whether the event is a press of the key
+func (k Key) Matches(e *tcell.EventKey) bool {
+	if (e.Modifiers()&tcell.ModAlt != 0) != k.Alt {
+		return false
+	}
+
+	if k.Key == tcell.KeyRune {
+		return e.Key() == tcell.KeyRune && e.Rune() == k.Rune
+	}
+
+	// terminals send Backspace as either of these
+	if k.Key == tcell.KeyBackspace2 {
+		return e.Key() == tcell.KeyBackspace || e.Key() == tcell.KeyBackspace2
+	}
+
+	return e.Key() == k.Key
+}
+
+// The keys bound to each Action
+type Keymap map[Action][]Key
+
+// The keys that each Action is bound to if it's not configured
+func DefaultKeymap() Keymap {
+	km := make(Keymap)
+
+	for _, info := range Actions {
+		for _, spec := range info.Defaults {
+			key, err := ParseKey(spec)
+
+			if err != nil {
+				panic(err)
+			}
+
+			km[info.Action] = append(km[info.Action], key)
+		}
+	}
+
+	return km
+}
+
+// Look up an Action by its name, which is case-insensitive
+func FindAction(name string) (ActionInfo, bool) {
+	for _, info := range Actions {
+		if string(info.Action) == strings.ToLower(name) {
+			return info, true
+		}
+	}
+
+	return ActionInfo{}, false
+}
+
+// Whether the event is a press of one of the Action's keys
+func (km Keymap) Matches(action Action, e *tcell.EventKey) bool {
+	for _, k := range km[action] {
+		if k.Matches(e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The Action's first key as a List item shortcut, or 0 if it has none
+func (km Keymap) Shortcut(action Action) rune {
+	for _, k := range km[action] {
+		if k.Key == tcell.KeyRune && !k.Alt {
+			return k.Rune
+		}
+	}
+
+	return 0
+}
+
+// The Action's keys for display, e.g. "alt+left, alt+b"
+func (km Keymap) Label(action Action) string {
+	names := make([]string, len(km[action]))
+	for i, k := range km[action] {
+		names[i] = k.String()
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Check that no key is bound to two Actions in overlapping scopes,
+// that List item shortcuts are plain characters,
+// and that no key used in lists clashes with their numbered options
+func (km Keymap) Validate() error {
+	var problems []string
+
+	for i, a := range Actions {
+		for _, k := range km[a.Action] {
+			if a.isShortcut() && (k.Key != tcell.KeyRune || k.Alt) {
+				problems = append(problems, fmt.Sprintf("%s is bound to '%s', but list shortcuts must be single characters", configKey(a.Action), k))
+			}
+
+			if a.Scope.Overlaps(ScopeList) && k.Key == tcell.KeyRune && !k.Alt && strings.ContainsRune(numberedOptionKeys, k.Rune) {
+				problems = append(problems, fmt.Sprintf("%s is bound to '%s', which lists use for numbered options", configKey(a.Action), k))
+			}
+		}
+
+		for _, b := range Actions[i+1:] {
+			if !a.Scope.Overlaps(b.Scope) {
+				continue
+			}
+
+			for _, ka := range km[a.Action] {
+				for _, kb := range km[b.Action] {
+					if ka == kb {
+						problems = append(problems, fmt.Sprintf("%s and %s are both bound to '%s'", configKey(a.Action), configKey(b.Action), ka))
+					}
+				}
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+
+	return fmt.Errorf("conflicting key bindings:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// The config key that binds the Action, e.g. KEY_MENU_ALBUMS
+func configKey(action Action) string {
+	return "KEY_" + strings.ToUpper(string(action))
+}
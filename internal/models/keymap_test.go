@@ -0,0 +1,206 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Key
+		wantErr bool
+	}{
+		{spec: "a", want: Key{Key: tcell.KeyRune, Rune: 'a'}},
+		{spec: "A", want: Key{Key: tcell.KeyRune, Rune: 'A'}},
+		{spec: "?", want: Key{Key: tcell.KeyRune, Rune: '?'}},
+		{spec: "é", want: Key{Key: tcell.KeyRune, Rune: 'é'}},
+		{spec: "space", want: Key{Key: tcell.KeyRune, Rune: ' '}},
+		{spec: "esc", want: Key{Key: tcell.KeyEscape}},
+		{spec: "Enter", want: Key{Key: tcell.KeyEnter}},
+		{spec: "f1", want: Key{Key: tcell.KeyF1}},
+		{spec: "F12", want: Key{Key: tcell.KeyF12}},
+		{spec: "ctrl+b", want: Key{Key: tcell.KeyCtrlB}},
+		{spec: "Ctrl+N", want: Key{Key: tcell.KeyCtrlN}},
+		{spec: "alt+left", want: Key{Key: tcell.KeyLeft, Alt: true}},
+		{spec: "alt+b", want: Key{Key: tcell.KeyRune, Rune: 'b', Alt: true}},
+		{spec: "alt+ctrl+b", want: Key{Key: tcell.KeyCtrlB, Alt: true}},
+		{spec: "alt+", want: Key{}, wantErr: true},
+		{spec: "", wantErr: true},
+		{spec: "f0", wantErr: true},
+		{spec: "f13", wantErr: true},
+		{spec: "ctrl+1", wantErr: true},
+		{spec: "ctrl+left", wantErr: true},
+		{spec: "shift+a", wantErr: true},
+		{spec: "nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseKey(tt.spec)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseKey(%q) = %+v, want an error", tt.spec, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseKey(%q) returned error: %v", tt.spec, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseKey(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyStringRoundTrips(t *testing.T) {
+	for _, spec := range []string{"a", "?", "space", "esc", "enter", "tab", "backspace", "f1", "f12", "ctrl+b", "alt+left", "alt+b"} {
+		key, err := ParseKey(spec)
+
+		if err != nil {
+			t.Fatalf("ParseKey(%q) returned error: %v", spec, err)
+		}
+
+		if got := key.String(); got != spec {
+			t.Errorf("ParseKey(%q).String() = %q", spec, got)
+		}
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		event *tcell.EventKey
+		want  bool
+	}{
+		{"same rune", "e", tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), true},
+		{"different rune", "e", tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone), false},
+		{"rune is case-sensitive", "e", tcell.NewEventKey(tcell.KeyRune, 'E', tcell.ModNone), false},
+		{"alt is required", "alt+b", tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone), false},
+		{"alt rune", "alt+b", tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), true},
+		{"alt isn't a plain rune", "b", tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), false},
+		{"special key", "alt+left", tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModAlt), true},
+		{"either backspace", "backspace", tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.spec)
+
+			if err != nil {
+				t.Fatalf("ParseKey(%q) returned error: %v", tt.spec, err)
+			}
+
+			if got := key.Matches(tt.event); got != tt.want {
+				t.Errorf("%q.Matches(%v) = %v, want %v", tt.spec, tt.event.Name(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeymapValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		// bindings that replace the defaults
+		bindings map[Action][]string
+		// substrings of the error, or none if the keymap is valid
+		wantErrs []string
+	}{
+		{name: "defaults"},
+		{
+			name:     "the same key in scopes that don't overlap",
+			bindings: map[Action][]string{ActionExport: {"a"}},
+		},
+		{
+			name:     "two table actions",
+			bindings: map[Action][]string{ActionExport: {"s"}},
+			wantErrs: []string{"KEY_EXPORT and KEY_SORT are both bound to 's'"},
+		},
+		{
+			name:     "a global action and a table action",
+			bindings: map[Action][]string{ActionHelp: {"e"}},
+			wantErrs: []string{"KEY_HELP and KEY_EXPORT are both bound to 'e'"},
+		},
+		{
+			name:     "a list action and a main menu item",
+			bindings: map[Action][]string{ActionListNext: {"a"}},
+			wantErrs: []string{"KEY_LIST_NEXT and KEY_MENU_PLAYLISTS are both bound to 'a'"},
+		},
+		{
+			name:     "a main menu item and a duplicate option don't overlap",
+			bindings: map[Action][]string{ActionDuplicatesMode: {"a"}},
+		},
+		{
+			name:     "a list shortcut that isn't a character",
+			bindings: map[Action][]string{ActionMenuAlbums: {"f2"}},
+			wantErrs: []string{"KEY_MENU_ALBUMS is bound to 'f2', but list shortcuts must be single characters"},
+		},
+		{
+			name:     "a list shortcut with alt",
+			bindings: map[Action][]string{ActionQuit: {"alt+q"}},
+			wantErrs: []string{"KEY_QUIT is bound to 'alt+q', but list shortcuts must be single characters"},
+		},
+		{
+			name:     "a numbered option key",
+			bindings: map[Action][]string{ActionListNext: {"1"}},
+			wantErrs: []string{"KEY_LIST_NEXT is bound to '1', which lists use for numbered options"},
+		},
+		{
+			name:     "a numbered option key in a table",
+			bindings: map[Action][]string{ActionExport: {"1"}},
+		},
+		{
+			name:     "every problem is reported",
+			bindings: map[Action][]string{ActionExport: {"s"}, ActionMenuAlbums: {"f2"}},
+			wantErrs: []string{"KEY_EXPORT and KEY_SORT", "KEY_MENU_ALBUMS is bound to 'f2'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km := DefaultKeymap()
+
+			for action, specs := range tt.bindings {
+				km[action] = nil
+
+				for _, spec := range specs {
+					key, err := ParseKey(spec)
+
+					if err != nil {
+						t.Fatalf("ParseKey(%q) returned error: %v", spec, err)
+					}
+
+					km[action] = append(km[action], key)
+				}
+			}
+
+			err := km.Validate()
+
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Errorf("Validate() returned error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("Validate() = nil, want an error containing %q", tt.wantErrs)
+			}
+
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ccb012100/go-playlist-search/internal/db"
 	"github.com/rivo/tview"
 )
 
@@ -12,55 +13,144 @@ type View struct {
 	App *tview.Application
 	// Grid at Application Root
 	Grid *tview.Grid
+	// main area of the Grid, holding a page for each screen in the History
+	Pages *tview.Pages
 	// footer for displaying messages
 	MessageBar *tview.TextView
 	// header at top of app
 	TitleBar *tview.TextView
-	// db file path
-	DB string
+	// connection to the Sqlite DB
+	DB *db.Repository
 	// Selection List
 	List *tview.List
+	// directory that exported tables are written to
+	ExportDir string
+	// groups of playlists that the Starred search and duplicate finder can be run against
+	PlaylistGroups []PlaylistGroup
+	// screens that have been displayed, for going back and forward
+	History *History
+	// keys bound to each Action
+	Keymap Keymap
+	// colors the UI is drawn with
+	Theme Theme
 }
 
 type Album struct {
-	Id          string
-	Name        string
-	TotalTracks int
-	ReleaseDate string
-	AlbumType   string
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	TotalTracks int    `json:"total_tracks,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	AlbumType   string `json:"album_type,omitempty"`
+	// primary artists of the album, separated by "; "
+	Artists string `json:"artists,omitempty"`
+}
+
+type Track struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	TrackNumber int    `json:"track_number,omitempty"`
+	// artists credited on the track, separated by "; "
+	Artists string `json:"artists,omitempty"`
+	// playlists containing the track, separated by "; "
+	Playlists   string `json:"playlists,omitempty"`
+	AlbumId     string `json:"album_id,omitempty"`
+	AlbumName   string `json:"album_name,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"`
+	DurationMs  int    `json:"duration_ms,omitempty"`
+	// position of the track in a playlist, starting at 1
+	Position int `json:"position,omitempty"`
+	// when the track was added to a playlist
+	AddedAt string `json:"added_at,omitempty"`
+}
+
+// A Playlist that a Track was added to
+type PlaylistAppearance struct {
+	PlaylistId   string `json:"playlist_id"`
+	PlaylistName string `json:"playlist_name"`
+	// position of the track in the playlist, starting at 1
+	Position int    `json:"position,omitempty"`
+	AddedAt  string `json:"added_at"`
 }
 
 type SimpleIdentifier struct {
-	Name string
-	Id   string
+	Name string `json:"name"`
+	Id   string `json:"id"`
 }
 
 type StarredPlaylistMatch struct {
-	PlaylistName string
-	TrackName    string
-	AlbumName    string
-	Artists      string
+	PlaylistName string `json:"playlist_name"`
+	TrackId      string `json:"track_id"`
+	TrackName    string `json:"track_name"`
+	AlbumName    string `json:"album_name"`
+	Artists      string `json:"artists"`
+	DurationMs   int    `json:"duration_ms"`
+}
+
+// Matches for a query across every searchable entity type
+type SearchResults struct {
+	Artists   []SimpleIdentifier
+	Albums    []Album
+	Tracks    []Track
+	Playlists []SimpleIdentifier
+	// the number of matches of each type, which is more than were returned if the search was limited
+	ArtistCount   int
+	AlbumCount    int
+	TrackCount    int
+	PlaylistCount int
+}
+
+// A single match from SearchResults, flattened so that every entity type shares the same fields
+type SearchResult struct {
+	// "artist", "album", "song" or "playlist"
+	Type    string `json:"type"`
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Details string `json:"details,omitempty"`
 }
 
 type DuplicateTrack struct {
-	Playlists string
-	TrackName string
-	Artists   string
-	AlbumName string
+	// summary of the Occurrences, separated by "; "
+	Playlists string `json:"playlists"`
+	TrackId   string `json:"track_id"`
+	TrackName string `json:"track_name"`
+	Artists   string `json:"artists"`
+	AlbumName string `json:"album_name"`
+	// every copy of the track, in playlist name and position order
+	Occurrences []PlaylistAppearance `json:"occurrences"`
 }
 
-func (v View) UpdateMessageBar(message string) {
-	v.MessageBar.SetText(fmt.Sprintf("%s => %s", time.Now().Format("03:04:05"), message))
+// Different tracks that are probably the same recording,
+// e.g. the single, album and remastered versions of a song
+type SimilarTracks struct {
+	// normalized name that the tracks share
+	Key    string         `json:"key"`
+	Tracks []SimilarTrack `json:"tracks"`
+}
+
+type SimilarTrack struct {
+	Track
+	// how similar the track is to the first track in its group, from 0 to 1
+	Similarity float64 `json:"similarity"`
+}
+
+// Year portion of the ReleaseDate, which can be "YYYY", "YYYY-MM" or "YYYY-MM-DD"
+func (a Album) ReleaseYear() string {
+	if len(a.ReleaseDate) < 4 {
+		return a.ReleaseDate
+	}
+
+	return a.ReleaseDate[:4]
 }
 
-func (v View) UpdateTitleBar(message string) {
-	v.TitleBar.SetText(message)
+func (v View) UpdateMessageBar(message string) {
+	v.MessageBar.SetTextColor(tview.Styles.PrimaryTextColor)
+	v.MessageBar.SetText(fmt.Sprintf("%s => %s", time.Now().Format("03:04:05"), message))
 }
 
-// Display the Primitive in the main panel of the app's Grid
-func (v View) SetMainPanel(p tview.Primitive) {
-	v.Grid.AddItem(p, 1, 0, 1, 1, 0, 0, true)
-	v.App.SetFocus(p)
+// Display the error in the message bar, so that the current screen stays usable
+func (v View) ShowError(err error) {
+	v.UpdateMessageBar(fmt.Sprintf("Error: %s", err))
+	v.MessageBar.SetTextColor(v.Theme.Error)
 }
 
 // ByReleaseDate implements sort.Interface based on the ReleaseDate field.
@@ -76,3 +166,45 @@ type ByName []Album
 func (a ByName) Len() int           { return len(a) }
 func (a ByName) Less(i, j int) bool { return a[i].Name < a[j].Name }
 func (a ByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// TracksByName implements sort.Interface based on the Name field.
+type TracksByName []Track
+
+func (t TracksByName) Len() int           { return len(t) }
+func (t TracksByName) Less(i, j int) bool { return t[i].Name < t[j].Name }
+func (t TracksByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// TracksByAlbum implements sort.Interface based on the AlbumName and TrackNumber fields.
+type TracksByAlbum []Track
+
+func (t TracksByAlbum) Len() int { return len(t) }
+func (t TracksByAlbum) Less(i, j int) bool {
+	if t[i].AlbumName != t[j].AlbumName {
+		return t[i].AlbumName < t[j].AlbumName
+	}
+
+	return t[i].TrackNumber < t[j].TrackNumber
+}
+func (t TracksByAlbum) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+
+// TracksByReleaseDate implements sort.Interface based on the ReleaseDate and TrackNumber fields.
+type TracksByReleaseDate []Track
+
+func (t TracksByReleaseDate) Len() int { return len(t) }
+func (t TracksByReleaseDate) Less(i, j int) bool {
+	if t[i].ReleaseDate != t[j].ReleaseDate {
+		return t[i].ReleaseDate < t[j].ReleaseDate
+	}
+
+	return t[i].TrackNumber < t[j].TrackNumber
+}
+func (t TracksByReleaseDate) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+
+// Smaller of a and b
+func Min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
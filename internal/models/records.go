@@ -0,0 +1,211 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Record is a result that can be written out as a row of text, e.g. as CSV.
+// Columns is the same for every value of a type, so it can be called on the zero value.
+type Record interface {
+	Columns() []string
+	Values() []string
+}
+
+// format an int field, leaving it blank if it wasn't loaded
+func intValue(i int) string {
+	if i == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(i)
+}
+
+func (a Album) Columns() []string {
+	return []string{"Id", "Name", "Artists", "Tracks", "Release Date", "Type"}
+}
+
+func (a Album) Values() []string {
+	return []string{a.Id, a.Name, a.Artists, intValue(a.TotalTracks), a.ReleaseDate, a.AlbumType}
+}
+
+func (t Track) Columns() []string {
+	return []string{"Position", "Id", "#", "Track", "Artists", "Album Id", "Album", "Release Date", "Duration (ms)", "Added At", "Playlists"}
+}
+
+func (t Track) Values() []string {
+	return []string{intValue(t.Position), t.Id, intValue(t.TrackNumber), t.Name, t.Artists, t.AlbumId, t.AlbumName, t.ReleaseDate, intValue(t.DurationMs), t.AddedAt, t.Playlists}
+}
+
+func (p PlaylistAppearance) Columns() []string {
+	return []string{"Playlist Id", "Playlist", "Position", "Added At"}
+}
+
+func (p PlaylistAppearance) Values() []string {
+	return []string{p.PlaylistId, p.PlaylistName, intValue(p.Position), p.AddedAt}
+}
+
+func (s SimpleIdentifier) Columns() []string {
+	return []string{"Id", "Name"}
+}
+
+func (s SimpleIdentifier) Values() []string {
+	return []string{s.Id, s.Name}
+}
+
+func (m StarredPlaylistMatch) Columns() []string {
+	return []string{"Playlist", "Track Id", "Track", "Album", "Artists", "Duration (ms)"}
+}
+
+func (m StarredPlaylistMatch) Values() []string {
+	return []string{m.PlaylistName, m.TrackId, m.TrackName, m.AlbumName, m.Artists, intValue(m.DurationMs)}
+}
+
+func (s SearchResult) Columns() []string {
+	return []string{"Type", "Id", "Name", "Details"}
+}
+
+func (s SearchResult) Values() []string {
+	return []string{s.Type, s.Id, s.Name, s.Details}
+}
+
+func (d DuplicateTrack) Columns() []string {
+	return []string{"Track Id", "Track", "Artists", "Album", "Playlists"}
+}
+
+func (d DuplicateTrack) Values() []string {
+	return []string{d.TrackId, d.TrackName, d.Artists, d.AlbumName, d.Playlists}
+}
+
+func (m PlaylistGroupMember) Columns() []string {
+	return []string{"Group", "Playlist Id", "Playlist"}
+}
+
+func (m PlaylistGroupMember) Values() []string {
+	return []string{m.Group, m.PlaylistId, m.PlaylistName}
+}
+
+// A SimilarTrack and the key of its group
+type similarTrackRecord struct {
+	Key string `json:"key"`
+	SimilarTrack
+}
+
+func (r similarTrackRecord) Columns() []string {
+	return []string{"Key", "Track Id", "Track", "Artists", "Album", "Duration (ms)", "Playlists", "Similarity"}
+}
+
+func (r similarTrackRecord) Values() []string {
+	t := r.SimilarTrack
+	return []string{r.Key, t.Id, t.Name, t.Artists, t.AlbumName, intValue(t.DurationMs), t.Playlists, t.SimilarityPercent()}
+}
+
+// Similarity as a whole percentage, e.g. "87%"
+func (t SimilarTrack) SimilarityPercent() string {
+	return fmt.Sprintf("%.0f%%", t.Similarity*100)
+}
+
+// Flatten the results into a single list, in the order Artists, Albums, Songs, Playlists
+func (r SearchResults) Flatten() []SearchResult {
+	var results []SearchResult
+
+	for _, a := range r.Artists {
+		results = append(results, SearchResult{Type: "artist", Id: a.Id, Name: a.Name})
+	}
+
+	for _, a := range r.Albums {
+		results = append(results, SearchResult{Type: "album", Id: a.Id, Name: a.Name, Details: a.Artists})
+	}
+
+	for _, t := range r.Tracks {
+		results = append(results, SearchResult{Type: "song", Id: t.Id, Name: t.Name, Details: t.Artists})
+	}
+
+	for _, p := range r.Playlists {
+		results = append(results, SearchResult{Type: "playlist", Id: p.Id, Name: p.Name})
+	}
+
+	return results
+}
+
+// The functions below convert slices of results into Records
+
+func AlbumRecords(albums []Album) []Record {
+	records := make([]Record, len(albums))
+	for i, a := range albums {
+		records[i] = a
+	}
+
+	return records
+}
+
+func TrackRecords(tracks []Track) []Record {
+	records := make([]Record, len(tracks))
+	for i, t := range tracks {
+		records[i] = t
+	}
+
+	return records
+}
+
+func PlaylistAppearanceRecords(appearances []PlaylistAppearance) []Record {
+	records := make([]Record, len(appearances))
+	for i, a := range appearances {
+		records[i] = a
+	}
+
+	return records
+}
+
+func SimpleIdentifierRecords(identifiers []SimpleIdentifier) []Record {
+	records := make([]Record, len(identifiers))
+	for i, s := range identifiers {
+		records[i] = s
+	}
+
+	return records
+}
+
+func StarredPlaylistMatchRecords(matches []StarredPlaylistMatch) []Record {
+	records := make([]Record, len(matches))
+	for i, m := range matches {
+		records[i] = m
+	}
+
+	return records
+}
+
+func SearchResultRecords(results []SearchResult) []Record {
+	records := make([]Record, len(results))
+	for i, r := range results {
+		records[i] = r
+	}
+
+	return records
+}
+
+func DuplicateTrackRecords(dupes []DuplicateTrack) []Record {
+	records := make([]Record, len(dupes))
+	for i, d := range dupes {
+		records[i] = d
+	}
+
+	return records
+}
+
+// Convert the groups into a Record per track
+func SimilarTracksRecords(groups []SimilarTracks) []Record {
+	var records []Record
+	for _, g := range groups {
+		for _, t := range g.Tracks {
+			records = append(records, similarTrackRecord{Key: g.Key, SimilarTrack: t})
+		}
+	}
+
+	return records
+}
+
+// Columns of the Records returned by SimilarTracksRecords
+func SimilarTracksColumns() []string {
+	return similarTrackRecord{}.Columns()
+}
@@ -0,0 +1,70 @@
+package models
+
+import "strconv"
+
+// Aggregate statistics about the whole DB
+type LibraryStats struct {
+	Playlists int `json:"playlists"`
+	Tracks    int `json:"tracks"`
+	Albums    int `json:"albums"`
+	Artists   int `json:"artists"`
+	// artists with the most tracks
+	TopArtists []Tally `json:"top_artists"`
+	// albums whose tracks were added to playlists the most times
+	TopAlbums []Tally `json:"top_albums"`
+	// tracks added to playlists in each month, as "YYYY-MM", oldest first
+	TracksAddedPerMonth []Tally `json:"tracks_added_per_month"`
+	// number of albums of each album_type, e.g. "album", "single" or "compilation"
+	AlbumTypes []Tally `json:"album_types"`
+}
+
+// A count of something, e.g. the number of tracks by an artist
+type Tally struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// A Tally and the statistic it belongs to
+type statRecord struct {
+	Statistic string `json:"statistic"`
+	Tally
+}
+
+func (r statRecord) Columns() []string {
+	return []string{"Statistic", "Name", "Count"}
+}
+
+func (r statRecord) Values() []string {
+	return []string{r.Statistic, r.Name, strconv.Itoa(r.Count)}
+}
+
+// Columns of the Records returned by LibraryStats.Records
+func LibraryStatsColumns() []string {
+	return statRecord{}.Columns()
+}
+
+// A Record per count, starting with the totals
+func (s LibraryStats) Records() []Record {
+	records := []Record{
+		statRecord{"total", Tally{"playlists", s.Playlists}},
+		statRecord{"total", Tally{"tracks", s.Tracks}},
+		statRecord{"total", Tally{"albums", s.Albums}},
+		statRecord{"total", Tally{"artists", s.Artists}},
+	}
+
+	for _, section := range []struct {
+		name    string
+		tallies []Tally
+	}{
+		{"top artist", s.TopArtists},
+		{"top album", s.TopAlbums},
+		{"tracks added", s.TracksAddedPerMonth},
+		{"album type", s.AlbumTypes},
+	} {
+		for _, t := range section.tallies {
+			records = append(records, statRecord{section.name, t})
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// The colors the app is drawn with, by what they're used for
+type Theme struct {
+	Name string
+	// default colors of text and widgets that aren't colored otherwise
+	Background tcell.Color
+	Text       tcell.Color
+	// background of input fields and modal dialogs
+	Contrast tcell.Color
+
+	// border of the Grid around the bars and main area
+	Border        tcell.Color
+	TitleBorder   tcell.Color
+	MessageBorder tcell.Color
+	// border of lists of results
+	ListBorder tcell.Color
+	// border of menus of options, e.g. Artist Info
+	MenuBorder tcell.Color
+
+	// table headers and the figures in screen headers
+	Header tcell.Color
+	// table cells and searched-for text
+	Cell tcell.Color
+	// secondary details, e.g. ids and notes
+	Muted tcell.Color
+	// errors in the MessageBar
+	Error tcell.Color
+	// bars of bar charts
+	Chart tcell.Color
+	// Quit and Reset list items
+	Quit  tcell.Color
+	Reset tcell.Color
+
+	// the selected list item or table row
+	SelectedText       tcell.Color
+	SelectedBackground tcell.Color
+}
+
+// The built-in Themes, by name
+var Themes = map[string]Theme{
+	"dark": {
+		Name:               "dark",
+		Background:         tcell.ColorBlack,
+		Text:               tcell.ColorWhite,
+		Contrast:           tcell.ColorBlue,
+		Border:             tcell.ColorMediumPurple,
+		TitleBorder:        tcell.ColorHotPink,
+		MessageBorder:      tcell.ColorDarkGreen,
+		ListBorder:         tcell.ColorDarkRed,
+		MenuBorder:         tcell.ColorDarkSeaGreen,
+		Header:             tcell.ColorOrange,
+		Cell:               tcell.ColorGreen,
+		Muted:              tcell.ColorGray,
+		Error:              tcell.ColorRed,
+		Chart:              tcell.ColorMediumPurple,
+		Quit:               tcell.ColorRed,
+		Reset:              tcell.ColorYellow,
+		SelectedText:       tcell.ColorBlack,
+		SelectedBackground: tcell.ColorGreen,
+	},
+	"light": {
+		Name:               "light",
+		Background:         tcell.ColorWhite,
+		Text:               tcell.ColorBlack,
+		Contrast:           tcell.ColorLightSteelBlue,
+		Border:             tcell.ColorPurple,
+		TitleBorder:        tcell.ColorMediumVioletRed,
+		MessageBorder:      tcell.ColorDarkGreen,
+		ListBorder:         tcell.ColorDarkRed,
+		MenuBorder:         tcell.ColorTeal,
+		Header:             tcell.ColorSaddleBrown,
+		Cell:               tcell.ColorDarkGreen,
+		Muted:              tcell.ColorDimGray,
+		Error:              tcell.ColorFireBrick,
+		Chart:              tcell.ColorPurple,
+		Quit:               tcell.ColorFireBrick,
+		Reset:              tcell.ColorDarkGoldenrod,
+		SelectedText:       tcell.ColorWhite,
+		SelectedBackground: tcell.ColorNavy,
+	},
+	"high-contrast": {
+		Name:               "high-contrast",
+		Background:         tcell.ColorBlack,
+		Text:               tcell.ColorWhite,
+		Contrast:           tcell.ColorNavy,
+		Border:             tcell.ColorWhite,
+		TitleBorder:        tcell.ColorWhite,
+		MessageBorder:      tcell.ColorWhite,
+		ListBorder:         tcell.ColorWhite,
+		MenuBorder:         tcell.ColorWhite,
+		Header:             tcell.ColorYellow,
+		Cell:               tcell.ColorWhite,
+		Muted:              tcell.ColorAqua,
+		Error:              tcell.ColorRed,
+		Chart:              tcell.ColorYellow,
+		Quit:               tcell.ColorRed,
+		Reset:              tcell.ColorYellow,
+		SelectedText:       tcell.ColorBlack,
+		SelectedBackground: tcell.ColorYellow,
+	},
+	// the terminal's own colors, for NO_COLOR.
+	// Selections are the exception, since tview can only highlight them with colors.
+	"monochrome": {
+		Name:               "monochrome",
+		Background:         tcell.ColorDefault,
+		Text:               tcell.ColorDefault,
+		Contrast:           tcell.ColorDefault,
+		Border:             tcell.ColorDefault,
+		TitleBorder:        tcell.ColorDefault,
+		MessageBorder:      tcell.ColorDefault,
+		ListBorder:         tcell.ColorDefault,
+		MenuBorder:         tcell.ColorDefault,
+		Header:             tcell.ColorDefault,
+		Cell:               tcell.ColorDefault,
+		Muted:              tcell.ColorDefault,
+		Error:              tcell.ColorDefault,
+		Chart:              tcell.ColorDefault,
+		Quit:               tcell.ColorDefault,
+		Reset:              tcell.ColorDefault,
+		SelectedText:       tcell.ColorBlack,
+		SelectedBackground: tcell.ColorWhite,
+	},
+}
+
+// The Theme used if none is configured
+const DefaultTheme = "dark"
+
+// Names of the built-in Themes, sorted
+func ThemeNames() []string {
+	var names []string
+	for name := range Themes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// The Theme's colors by the names used to configure them, e.g. "title_border"
+func (t *Theme) Colors() map[string]*tcell.Color {
+	return map[string]*tcell.Color{
+		"background":          &t.Background,
+		"text":                &t.Text,
+		"contrast":            &t.Contrast,
+		"border":              &t.Border,
+		"title_border":        &t.TitleBorder,
+		"message_border":      &t.MessageBorder,
+		"list_border":         &t.ListBorder,
+		"menu_border":         &t.MenuBorder,
+		"header":              &t.Header,
+		"cell":                &t.Cell,
+		"muted":               &t.Muted,
+		"error":               &t.Error,
+		"chart":               &t.Chart,
+		"quit":                &t.Quit,
+		"reset":               &t.Reset,
+		"selected_text":       &t.SelectedText,
+		"selected_background": &t.SelectedBackground,
+	}
+}
+
+// Make the Theme's colors tview's defaults, for the widgets created after this is called
+func (t Theme) Apply() {
+	tview.Styles.PrimitiveBackgroundColor = t.Background
+	tview.Styles.ContrastBackgroundColor = t.Contrast
+	tview.Styles.BorderColor = t.Border
+	tview.Styles.TitleColor = t.Text
+	tview.Styles.GraphicsColor = t.Border
+	tview.Styles.PrimaryTextColor = t.Text
+	tview.Styles.SecondaryTextColor = t.Header
+	tview.Styles.TertiaryTextColor = t.Cell
+	tview.Styles.InverseTextColor = t.SelectedText
+	tview.Styles.ContrastSecondaryTextColor = t.Muted
+}
+
+// Style of the selected table row
+func (t Theme) SelectedStyle() tcell.Style {
+	return tcell.StyleDefault.Foreground(t.SelectedText).Background(t.SelectedBackground)
+}
+
+// The color as a tview color tag, e.g. "orange" or "#ffa501", or "-" for the default color
+func ColorTag(c tcell.Color) string {
+	if c == tcell.ColorDefault {
+		return "-"
+	}
+
+	// named colors are kept as names, so that the terminal's palette is used for them
+	for name, named := range tcell.ColorNames {
+		if named == c {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("#%06x", c.Hex())
+}
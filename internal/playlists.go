@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/ccb012100/go-playlist-search/internal/data"
 	"github.com/ccb012100/go-playlist-search/internal/models"
@@ -10,42 +12,70 @@ import (
 )
 
 func SearchForPlaylists(v *models.View) {
+	v.UpdateTitleBar("Search Playlists")
+
 	input := tview.NewInputField()
-	// TODO: set minimum input length
-	input.SetLabel("Search for a playlist: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
-		v.UpdateMessageBar(fmt.Sprintf("key = %v", key))
+	results := newList(v).ShowSecondaryText(false)
+	results.SetBorder(true).SetTitle("Matching Playlists")
+	results.SetInputCapture(backToInputFunc(v, input))
+
+	search := newLiveSearch(v.App, func(ctx context.Context, query string) func() {
+		playlists, err := data.SearchPlaylists(ctx, query, v.DB)
+
+		return func() {
+			if err != nil {
+				v.ShowError(fmt.Errorf("could not search Playlists: %w", err))
+				return
+			}
+
+			results.Clear().SetTitle(fmt.Sprintf("%d Playlists matching '%s'", len(playlists), query))
+			for _, plist := range playlists {
+				p := plist
+				results.AddItem(p.Name, p.Id, 0, func() { SelectPlaylist(v, p) })
+			}
+		}
+	}, func() { results.Clear().SetTitle("Matching Playlists") })
+
+	input.SetLabel("Search for a playlist: ").SetFieldWidth(50).SetChangedFunc(search.Update).SetDoneFunc(func(key tcell.Key) {
 		switch key {
 		case tcell.KeyEscape:
-			GoToMainMenu(v)
+			search.Stop()
+			v.Back()
 		case tcell.KeyEnter:
+			search.Stop()
 			ShowPlaylistSearchResults(v, input.GetText())
 		}
 	})
 
-	v.SetMainPanel(input)
+	v.PushScreen("playlist-search", liveSearchLayout(v, input, results), screenKeys(liveSearchKeys, openRowKeys)...)
 }
 
 func ShowPlaylistSearchResults(v *models.View, query string) {
-	v.UpdateMessageBar(fmt.Sprintf("func ShowPlaylists() query='%s'", query))
+	playlists, err := data.SearchPlaylists(context.Background(), query, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search Playlists: %w", err))
+		return
+	}
 
-	playlists := data.SearchPlaylists(query, v.DB)
+	v.UpdateMessageBar(fmt.Sprintf("Found %d Playlists matching '%s'", len(playlists), query))
 
 	// display message if there are no matches
 	if len(playlists) == 0 {
 		textView := tview.NewTextView().SetDynamicColors(true)
-		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(tcell.ColorDarkRed)
-		textView.SetText(fmt.Sprintf("There are no matches for the query [green:-:b]%s[-]", query))
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no matches for the query %s", colorText(v.Theme.Cell, "b", query)))
 
 		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
 			switch e.Key() {
 			case tcell.KeyESC:
-				v.SetMainPanel(v.List)
+				v.PopScreen()
 			}
 
 			return e
 		})
 
-		v.SetMainPanel(textView)
+		v.PushScreen("no-results", textView, escKeys...)
 		return
 	}
 
@@ -59,78 +89,218 @@ func displayPlaylists(v *models.View, playlists []models.SimpleIdentifier) {
 		return
 	}
 
-	v.List.Clear().SetTitle("Playlists")
+	v.List = NewList(v)
+	v.List.SetTitle("Playlists")
 	for _, plist := range playlists {
 		p := plist
 		v.List.AddItem(p.Name, p.Id, 0, func() { SelectPlaylist(v, p) })
 	}
 	AddQuitToHomeOption(v.List, v)
-	AddResetOption(v.List, func() { SearchForPlaylists(v) })
+	AddResetOption(v, v.List, func() { SearchForPlaylists(v) })
 
-	v.SetMainPanel(v.List)
+	v.PushScreen("playlist-results", v.List, screenKeys(listKeys, quitToMenuKeys, resetKeys)...)
 }
 
+// keys of a playlist's tracks, other than opening rows, exporting and going back, for its help
+var playlistKeys = []models.ScreenAction{{Action: models.ActionPlaylistDuplicates}, {Action: models.ActionPlaylistCompare}}
+
 func SelectPlaylist(v *models.View, playlist models.SimpleIdentifier) {
 	v.UpdateTitleBar(playlist.Name)
-	var textView = tview.NewTextView()
+	v.UpdateMessageBar(fmt.Sprintf("Selected playlist %s %s", playlist.Id, playlist.Name))
 
-	textView.SetText(fmt.Sprintf("Selected playlist id='%s', name='%s'", playlist.Id, playlist.Name)).
-		SetTitle(fmt.Sprintf("Selected Playlist: %s", playlist.Name))
+	tracks, err := data.GetPlaylistTracks(playlist.Id, v.DB)
 
-	textView.SetInputCapture(BackToViewListFunc(v))
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Playlist tracks: %w", err))
+		return
+	}
 
-	v.SetMainPanel(textView)
+	displayPlaylistTracks(v, playlist, tracks)
 }
 
-func SearchStarredPlaylists(v *models.View) {
+func displayPlaylistTracks(v *models.View, playlist models.SimpleIdentifier, tracks []models.Track) {
+	runtime := 0
+	for _, t := range tracks {
+		runtime += t.DurationMs
+	}
+
+	header := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	header.SetText(fmt.Sprintf("%s\n%d tracks | %s total run time | %s: find duplicates | %s: compare with other playlists",
+		colorText(v.Theme.Header, "b", tview.Escape(playlist.Name)), len(tracks), formatDuration(runtime), v.Keymap.Label(models.ActionPlaylistDuplicates), v.Keymap.Label(models.ActionPlaylistCompare)))
+
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("#").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+	table.SetCell(0, 1, tview.NewTableCell("Track").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 2, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Album").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 4, tview.NewTableCell("Added").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+
+	// set table contents
+	for i := 0; i < len(tracks); i++ {
+		track := tracks[i]
+
+		// use i+1 to offset for header row
+		table.SetCell(i+1, 0, tview.NewTableCell(padRight(strconv.Itoa(track.Position))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(0))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(track.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(track.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(track.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 4, tview.NewTableCell(padLeft(formatDate(track.AddedAt))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+	}
+
+	// open the Song when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectSong(v, tracks[row-1].Id, tracks[row-1].Name)
+		}
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 2, 0, false).
+		AddItem(table, 0, 1, true)
+
+	back := BackFunc(v)
+	// the Export action exports the tracks, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{
+			name:    "playlist",
+			columns: models.Track{}.Columns(),
+			records: models.TrackRecords(tracks),
+			entries: models.TrackEntries(tracks),
+			title:   playlist.Name,
+		}
+	}, func(e *tcell.EventKey) *tcell.EventKey {
+		switch {
+		// find tracks that were added to the playlist more than once
+		case v.Keymap.Matches(models.ActionPlaylistDuplicates, e):
+			showDuplicateSongsInPlaylist(v, playlist)
+			return nil
+		// compare the playlist with others
+		case v.Keymap.Matches(models.ActionPlaylistCompare, e):
+			choosePlaylistsToCompare(v, playlist)
+			return nil
+		}
+
+		return back(e)
+	}))
+
+	v.PushScreen("playlist", flex, screenKeys(openRowKeys, playlistKeys, exportKeys, backKeys)...)
+}
+
+func SearchStarredPlaylists(v *models.View, group models.PlaylistGroup) {
+	v.UpdateTitleBar(fmt.Sprintf("Search %s Playlists", group.Name))
+
 	input := tview.NewInputField()
-	// TODO: set minimum input length
-	input.SetLabel("Search Starred playlists: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
-		v.UpdateMessageBar(fmt.Sprintf("key = %v", key))
+	results := newTable(v).SetBorders(true)
+	results.SetBorder(true).SetTitle(fmt.Sprintf("Matches in %s Playlists", group.Name))
+
+	// the displayed matches, for exporting
+	var displayed []models.StarredPlaylistMatch
+	var displayedQuery string
+
+	search := newLiveSearch(v.App, func(ctx context.Context, query string) func() {
+		matches, err := data.SearchPlaylistGroup(ctx, group, query, v.DB)
+
+		return func() {
+			if err != nil {
+				v.ShowError(fmt.Errorf("could not search %s Playlists: %w", group.Name, err))
+				return
+			}
+
+			displayed, displayedQuery = matches, query
+			fillStarredPlaylistMatchesTable(v, results, matches)
+			results.SetTitle(fmt.Sprintf("%d matches in %s Playlists for '%s'", len(matches), group.Name, query))
+		}
+	}, func() {
+		displayed, displayedQuery = nil, ""
+		results.Clear().SetTitle(fmt.Sprintf("Matches in %s Playlists", group.Name))
+	})
+
+	input.SetLabel(fmt.Sprintf("Search %s playlists: ", group.Name)).SetFieldWidth(50).SetChangedFunc(search.Update).SetDoneFunc(func(key tcell.Key) {
 		switch key {
 		case tcell.KeyEscape:
-			GoToMainMenu(v)
+			search.Stop()
+			v.Back()
 		case tcell.KeyEnter:
-			ShowStarredPlaylistSearchResults(v, input.GetText())
+			search.Stop()
+			ShowStarredPlaylistSearchResults(v, group, input.GetText())
 		}
 	})
 
-	v.SetMainPanel(input)
+	layout := liveSearchLayout(v, input, results)
+
+	// the Export action exports the matches, Esc or Backtab returns to the input field
+	results.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{
+			name:    "starred-matches",
+			columns: models.StarredPlaylistMatch{}.Columns(),
+			records: models.StarredPlaylistMatchRecords(displayed),
+			entries: models.StarredPlaylistMatchEntries(displayed),
+			title:   fmt.Sprintf("%s Playlist matches for '%s'", group.Name, displayedQuery),
+		}
+	}, backToInputFunc(v, input)))
+
+	v.PushScreen("starred-search", layout, screenKeys(liveSearchKeys, exportKeys)...)
 }
 
-func ShowStarredPlaylistSearchResults(v *models.View, query string) {
-	v.UpdateTitleBar(fmt.Sprintf("Items in Starred Playlists matching '%s'", query))
+func ShowStarredPlaylistSearchResults(v *models.View, group models.PlaylistGroup, query string) {
+	v.UpdateTitleBar(fmt.Sprintf("Items in %s Playlists matching '%s'", group.Name, query))
 
-	matches := data.SearchStarredPlaylists(query, v.DB)
+	matches, err := data.SearchPlaylistGroup(context.Background(), group, query, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search %s Playlists: %w", group.Name, err))
+		return
+	}
 
 	if len(matches) == 0 {
 		textView := tview.NewTextView().SetDynamicColors(true)
-		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(tcell.ColorDarkRed)
-		textView.SetText(fmt.Sprintf("There are no matches for the query [green:-:b]%s[-]", query))
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no matches for the query %s", colorText(v.Theme.Cell, "b", query)))
 
 		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
 			switch e.Key() {
 			case tcell.KeyESC:
-				v.SetMainPanel(v.List)
+				v.PopScreen()
 			}
 
 			return e
 		})
 
-		v.SetMainPanel(textView)
+		v.PushScreen("no-results", textView, escKeys...)
 		return
 	}
 
-	displayStarredPlaylistMatches(v, matches)
+	displayStarredPlaylistMatches(v, group, query, matches)
 }
 
-func displayStarredPlaylistMatches(v *models.View, matches []models.StarredPlaylistMatch) {
-	table := tview.NewTable().SetBorders(true)
+func displayStarredPlaylistMatches(v *models.View, group models.PlaylistGroup, query string, matches []models.StarredPlaylistMatch) {
+	table := newTable(v).SetBorders(true)
+	fillStarredPlaylistMatchesTable(v, table, matches)
+
+	// the Export action exports the matches, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{
+			name:    "starred-matches",
+			columns: models.StarredPlaylistMatch{}.Columns(),
+			records: models.StarredPlaylistMatchRecords(matches),
+			entries: models.StarredPlaylistMatchEntries(matches),
+			title:   fmt.Sprintf("%s Playlist matches for '%s'", group.Name, query),
+		}
+	}, BackFunc(v)))
+
+	v.PushScreen("starred-results", table, screenKeys(exportKeys, backKeys)...)
+}
+
+func fillStarredPlaylistMatchesTable(v *models.View, table *tview.Table, matches []models.StarredPlaylistMatch) {
+	table.Clear()
+
 	// set header row
-	table.SetCell(0, 0, tview.NewTableCell("Playlist").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 1, tview.NewTableCell("Track").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 2, tview.NewTableCell("Album").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 3, tview.NewTableCell("Artists").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 0, tview.NewTableCell("Playlist").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 1, tview.NewTableCell("Track").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 2, tview.NewTableCell("Album").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 3, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
 
 	// set table contents
 	// start row at 1 to offset for header
@@ -138,13 +308,9 @@ func displayStarredPlaylistMatches(v *models.View, matches []models.StarredPlayl
 		match := matches[i]
 
 		// use i+1 to offset for header row
-		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(match.PlaylistName)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(match.TrackName)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(match.AlbumName)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(match.Artists)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
+		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(match.PlaylistName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(match.TrackName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(match.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(match.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
 	}
-
-	table.SetInputCapture(BackToViewListFunc(v))
-
-	v.SetMainPanel(table)
 }
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// keys of the everything search's input field and results, for its help
+var everythingSearchKeys = []models.ScreenAction{
+	{Keys: "Enter", Description: "Search, or open the selected result"},
+	{Keys: "Esc", Description: "Go back to the previous screen, from the search field"},
+	{Keys: "Tab", Description: "Move from the search field to the results"},
+	{Keys: "Esc, Backtab", Description: "Move from the results to the search field"},
+}
+
+func SearchForEverything(v *models.View) {
+	v.UpdateTitleBar("Search Everything")
+
+	input := tview.NewInputField()
+	table := newTable(v).SetSelectable(true, false)
+	table.SetBorder(true).SetTitle("Results")
+
+	// TODO: set minimum input length
+	input.SetLabel("Search everything: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			v.Back()
+		case tcell.KeyEnter:
+			if ShowEverythingSearchResults(v, table, input.GetText()) {
+				v.App.SetFocus(table)
+			}
+		case tcell.KeyTab:
+			v.App.SetFocus(table)
+		}
+	})
+
+	// return to the input field to search again
+	table.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+		switch e.Key() {
+		case tcell.KeyESC, tcell.KeyBacktab:
+			v.App.SetFocus(input)
+			return nil
+		}
+
+		return e
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(table, 0, 1, false)
+
+	v.PushScreen("everything-search", flex, everythingSearchKeys...)
+}
+
+// Fill the table with the matches for the query, grouped by entity type.
+// Returns false if there was nothing to display.
+func ShowEverythingSearchResults(v *models.View, table *tview.Table, query string) bool {
+	v.SetScreenTitle(fmt.Sprintf("Everything matching '%s'", query))
+
+	if strings.TrimSpace(query) == "" {
+		v.UpdateMessageBar("Type something to search for")
+		return false
+	}
+
+	results, err := data.SearchEverything(context.Background(), query, data.SearchEverythingLimit, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search everything: %w", err))
+		return false
+	}
+
+	table.Clear()
+
+	// what to do when each row is selected; nil for rows that can't be selected
+	var actions []func()
+
+	addGroup := func(name string, count int) {
+		row := len(actions)
+		table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf("%s (%d)", name, count)).SetTextColor(v.Theme.Header).SetAttributes(tcell.AttrBold).SetSelectable(false))
+		table.SetCell(row, 1, tview.NewTableCell("").SetSelectable(false))
+		actions = append(actions, nil)
+	}
+
+	addResult := func(name string, details string, action func()) {
+		row := len(actions)
+		table.SetCell(row, 0, tview.NewTableCell(padLeft(name)).SetTextColor(v.Theme.Cell).SetExpansion(1))
+		table.SetCell(row, 1, tview.NewTableCell(padLeft(details)).SetTextColor(v.Theme.Muted).SetExpansion(1))
+		actions = append(actions, action)
+	}
+
+	addOverflow := func(count int, displayed int, entryName string) {
+		if count <= displayed {
+			return
+		}
+
+		row := len(actions)
+		table.SetCell(row, 0, tview.NewTableCell(padLeft(fmt.Sprintf("... %d more, use %s search to see them all", count-displayed, entryName))).SetTextColor(v.Theme.Muted).SetSelectable(false))
+		table.SetCell(row, 1, tview.NewTableCell("").SetSelectable(false))
+		actions = append(actions, nil)
+	}
+
+	addGroup("Artists", results.ArtistCount)
+	for _, artist := range results.Artists {
+		a := artist
+		addResult(a.Name, "", func() { SelectArtist(v, a) })
+	}
+	addOverflow(results.ArtistCount, len(results.Artists), "Artists")
+
+	addGroup("Albums", results.AlbumCount)
+	for _, album := range results.Albums {
+		a := album
+		addResult(a.Name, fmt.Sprintf("%s (%s, %s)", a.Artists, a.ReleaseYear(), a.AlbumType), func() { SelectAlbum(v, a.Id, a.Name) })
+	}
+	addOverflow(results.AlbumCount, len(results.Albums), "Albums")
+
+	addGroup("Songs", results.TrackCount)
+	for _, track := range results.Tracks {
+		t := track
+		addResult(t.Name, fmt.Sprintf("%s - %s", t.Artists, t.AlbumName), func() { SelectSong(v, t.Id, t.Name) })
+	}
+	addOverflow(results.TrackCount, len(results.Tracks), "Songs")
+
+	addGroup("Playlists", results.PlaylistCount)
+	for _, playlist := range results.Playlists {
+		p := playlist
+		addResult(p.Name, "", func() { SelectPlaylist(v, p) })
+	}
+	addOverflow(results.PlaylistCount, len(results.Playlists), "Playlists")
+
+	total := results.ArtistCount + results.AlbumCount + results.TrackCount + results.PlaylistCount
+	title := fmt.Sprintf("%d results", total)
+
+	table.SetTitle(title)
+
+	table.SetSelectedFunc(func(row int, column int) {
+		if row < len(actions) && actions[row] != nil {
+			actions[row]()
+		}
+	})
+
+	if total == 0 {
+		v.UpdateMessageBar(fmt.Sprintf("There are no matches for the query '%s'", query))
+		return false
+	}
+
+	v.UpdateMessageBar(fmt.Sprintf("Found %s matching '%s'", title, query))
+
+	// select the first result rather than the first group header
+	for row, action := range actions {
+		if action != nil {
+			table.Select(row, 0)
+			break
+		}
+	}
+
+	return true
+}
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/ccb012100/go-playlist-search/internal/data"
 	"github.com/ccb012100/go-playlist-search/internal/models"
@@ -10,53 +12,203 @@ import (
 )
 
 func SearchForSongs(v *models.View) {
-	box := tview.NewBox().SetBorder(true).SetTitle("Songs")
-	box.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
-		switch e.Key() {
-		case tcell.KeyESC:
-			GoToMainMenu(v)
+	v.UpdateTitleBar("Search Songs")
+
+	input := tview.NewInputField()
+	// TODO: set minimum input length
+	input.SetLabel("Search for songs: ").SetFieldWidth(50).SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			v.Back()
+		case tcell.KeyEnter:
+			ShowSongSearchResults(v, input.GetText())
 		}
+	})
+
+	v.PushScreen("song-search", input, searchKeys...)
+}
+
+func ShowSongSearchResults(v *models.View, query string) {
+	v.UpdateTitleBar(fmt.Sprintf("Songs matching '%s'", query))
+
+	tracks, err := data.SearchTracks(context.Background(), query, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not search Songs: %w", err))
+		return
+	}
+
+	v.UpdateMessageBar(fmt.Sprintf("Found %d Songs matching '%s'", len(tracks), query))
+
+	// show message if 0 results
+	if len(tracks) == 0 {
+		textView := tview.NewTextView().SetDynamicColors(true)
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("There are no Songs matching %s", colorText(v.Theme.Cell, "b", query)))
+
+		textView.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+			switch e.Key() {
+			case tcell.KeyESC:
+				v.PopScreen()
+			}
+
+			return e
+		})
+
+		v.PushScreen("no-results", textView, escKeys...)
+		return
+	}
 
-		return e
+	// if there's only 1 match, just select it
+	if len(tracks) == 1 {
+		SelectSong(v, tracks[0].Id, tracks[0].Name)
+		return
+	}
+
+	displaySongs(v, tracks)
+}
+
+func displaySongs(v *models.View, tracks []models.Track) {
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("Track").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 1, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 2, tview.NewTableCell("Album").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Release Date").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+
+	// set table contents
+	for i := 0; i < len(tracks); i++ {
+		track := tracks[i]
+
+		// use i+1 to offset for header row
+		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(track.Name)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(track.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(track.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(track.ReleaseDate)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+	}
+
+	// open the Song when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectSong(v, tracks[row-1].Id, tracks[row-1].Name)
+		}
 	})
-	v.SetMainPanel(box)
+
+	table.SetInputCapture(BackFunc(v))
+
+	v.PushScreen("song-results", table, screenKeys(openRowKeys, backKeys)...)
 }
 
+// Display every Playlist the Song appears in
 func SelectSong(v *models.View, id string, name string) {
+	v.UpdateTitleBar(fmt.Sprintf("Playlists containing '%s'", name))
+	v.UpdateMessageBar(fmt.Sprintf("Selected song %s %s", id, name))
 
-}
+	appearances, err := data.GetPlaylistsContainingTrack(id, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load Playlists containing Song: %w", err))
+		return
+	}
+
+	if len(appearances) == 0 {
+		textView := tview.NewTextView().SetDynamicColors(true)
+		textView.SetTitle("No matches").SetBorder(true).SetBorderColor(v.Theme.ListBorder)
+		textView.SetText(fmt.Sprintf("%s is not in any Playlists %s", colorText(v.Theme.Cell, "b", tview.Escape(name)), colorText(v.Theme.Muted, "", fmt.Sprintf("(Id = %s)", id))))
+		textView.SetInputCapture(BackFunc(v))
+
+		v.PushScreen("no-results", textView, backKeys...)
+		return
+	}
+
+	table := newTable(v).SetBorders(true)
+	// set header row
+	table.SetCell(0, 0, tview.NewTableCell("Playlist").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 1, tview.NewTableCell("Added At").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
 
-func ShowDuplicateSongsinStarredPlaylists(v *models.View) {
-	v.UpdateMessageBar("func ShowDuplicateSongsinStarredPlaylists()")
+	// set table contents
+	for i := 0; i < len(appearances); i++ {
+		appearance := appearances[i]
 
-	duplicates := data.GetDuplicateTracksInStarredPlaylists(v.DB)
+		// use i+1 to offset for header row
+		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(appearance.PlaylistName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(appearance.AddedAt)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+	}
+
+	// open the Playlist when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			a := appearances[row-1]
+			SelectPlaylist(v, models.SimpleIdentifier{Id: a.PlaylistId, Name: a.PlaylistName})
+		}
+	})
 
-	v.UpdateTitleBar(fmt.Sprintf("%d duplicate songs in Starred Playlists", len(duplicates)))
+	table.SetInputCapture(BackFunc(v))
+
+	v.PushScreen("song", table, screenKeys(openRowKeys, backKeys)...)
+}
+
+// Display the duplicate Songs found in the playlists described by where, e.g. "all Playlists"
+func showDuplicateSongs(v *models.View, where string, duplicates []models.DuplicateTrack, err error) {
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not load duplicate Songs: %w", err))
+		return
+	}
+
+	v.UpdateTitleBar(fmt.Sprintf("%d duplicate songs in %s", len(duplicates), where))
 
 	displayDuplicateSongs(v, duplicates)
 }
 
+// Display each duplicate Song with a row per copy, so it's clear which copies to remove
 func displayDuplicateSongs(v *models.View, dupes []models.DuplicateTrack) {
-	table := tview.NewTable().SetBorders(true)
+	table := newTable(v).SetBorders(true)
 	// set header row
-	table.SetCell(0, 0, tview.NewTableCell("Track Name").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 1, tview.NewTableCell("Artists").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 2, tview.NewTableCell("Album Name").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
-	table.SetCell(0, 3, tview.NewTableCell("Playlists").SetTextColor(tcell.ColorOrange).SetAlign(tview.AlignCenter).SetExpansion(1))
+	table.SetCell(0, 0, tview.NewTableCell("Track Name").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 1, tview.NewTableCell("Artists").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 2, tview.NewTableCell("Album Name").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 3, tview.NewTableCell("Playlist").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(2))
+	table.SetCell(0, 4, tview.NewTableCell("#").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(0))
+	table.SetCell(0, 5, tview.NewTableCell("Added").SetTextColor(v.Theme.Header).SetAlign(tview.AlignCenter).SetExpansion(1))
+
+	// the copy displayed in each row, offset for the header row
+	var copies []models.PlaylistAppearance
 
 	// set table contents
-	// start row at 1 to offset for header
-	for i := 0; i < len(dupes); i++ {
-		dupe := dupes[i]
+	for _, dupe := range dupes {
+		for i, o := range dupe.Occurrences {
+			// use len(copies)+1 to offset for header row
+			row := len(copies) + 1
 
-		// use i+1 to offset for header row
-		table.SetCell(i+1, 0, tview.NewTableCell(padLeft(dupe.TrackName)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 1, tview.NewTableCell(padLeft(dupe.Artists)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 2, tview.NewTableCell(padLeft(dupe.AlbumName)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
-		table.SetCell(i+1, 3, tview.NewTableCell(padLeft(dupe.Playlists)).SetTextColor(tcell.ColorGreen).SetAlign(tview.AlignLeft).SetExpansion(1))
+			// only the first copy's row names the Song
+			if i == 0 {
+				table.SetCell(row, 0, tview.NewTableCell(padLeft(dupe.TrackName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+				table.SetCell(row, 1, tview.NewTableCell(padLeft(dupe.Artists)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+				table.SetCell(row, 2, tview.NewTableCell(padLeft(dupe.AlbumName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			}
+
+			table.SetCell(row, 3, tview.NewTableCell(padLeft(o.PlaylistName)).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(2))
+			table.SetCell(row, 4, tview.NewTableCell(padRight(strconv.Itoa(o.Position))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignRight).SetExpansion(0))
+			table.SetCell(row, 5, tview.NewTableCell(padLeft(formatDate(o.AddedAt))).SetTextColor(v.Theme.Cell).SetAlign(tview.AlignLeft).SetExpansion(1))
+
+			copies = append(copies, o)
+		}
 	}
 
-	table.SetInputCapture(BackToViewListFunc(v))
+	// open the Playlist containing the copy when its row is selected
+	table.SetSelectable(true, false).SetFixed(1, 0).Select(1, 0).SetSelectedFunc(func(row int, column int) {
+		// offset for header row
+		if row > 0 {
+			SelectPlaylist(v, models.SimpleIdentifier{Id: copies[row-1].PlaylistId, Name: copies[row-1].PlaylistName})
+		}
+	})
+
+	// the Export action exports the duplicates, Esc returns to the previous screen
+	table.SetInputCapture(exportTableFunc(v, func() tableExport {
+		return tableExport{name: "duplicates", columns: models.DuplicateTrack{}.Columns(), records: models.DuplicateTrackRecords(dupes)}
+	}, BackFunc(v)))
 
-	v.SetMainPanel(table)
+	v.PushScreen("duplicate-songs", table, screenKeys(openRowKeys, exportKeys, backKeys)...)
 }
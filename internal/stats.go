@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ccb012100/go-playlist-search/internal/data"
+	"github.com/ccb012100/go-playlist-search/internal/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// width of the longest bar in a bar chart
+const barWidth = 20
+
+// keys of the statistics screen, other than exporting and going back, for its help
+var libraryStatsKeys = []models.ScreenAction{{Keys: "Tab, Backtab", Description: "Move between the charts"}}
+
+// Display aggregate statistics about the DB
+func ShowLibraryStats(v *models.View) {
+	stats, err := data.GetLibraryStats(data.StatsLimit, v.DB)
+
+	if err != nil {
+		v.ShowError(fmt.Errorf("could not get Library Statistics: %w", err))
+		return
+	}
+
+	v.UpdateTitleBar("Library Statistics")
+
+	displayLibraryStats(v, stats)
+}
+
+func displayLibraryStats(v *models.View, stats models.LibraryStats) {
+	header := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
+	count := func(n int) string { return colorText(v.Theme.Header, "b", strconv.Itoa(n)) }
+
+	header.SetText(fmt.Sprintf("%s playlists | %s tracks | %s albums | %s artists\n%s",
+		count(stats.Playlists), count(stats.Tracks), count(stats.Albums), count(stats.Artists),
+		colorText(v.Theme.Muted, "", fmt.Sprintf("Tab: next chart | %s: export | Esc: back", v.Keymap.Label(models.ActionExport)))))
+
+	charts := []*tview.Table{
+		createBarChart(v, fmt.Sprintf("Top %d Artists by Tracks", data.StatsLimit), stats.TopArtists),
+		createBarChart(v, fmt.Sprintf("Top %d Albums by Playlist Appearances", data.StatsLimit), stats.TopAlbums),
+		createBarChart(v, "Tracks Added per Month", stats.TracksAddedPerMonth),
+		createBarChart(v, "Album Types", stats.AlbumTypes),
+	}
+
+	grid := tview.NewGrid().SetRows(2, 0, 0).SetColumns(0, 0).
+		AddItem(header, 0, 0, 1, 2, 0, 0, false).
+		AddItem(charts[0], 1, 0, 1, 1, 0, 0, true).
+		AddItem(charts[1], 1, 1, 1, 1, 0, 0, false).
+		AddItem(charts[2], 2, 0, 1, 1, 0, 0, false).
+		AddItem(charts[3], 2, 1, 1, 1, 0, 0, false)
+
+	// Tab and Backtab move between the charts, the Export action exports the statistics, Esc returns to the previous screen
+	back := exportTableFunc(v, func() tableExport {
+		return tableExport{name: "stats", columns: models.LibraryStatsColumns(), records: stats.Records()}
+	}, BackFunc(v))
+
+	for i, chart := range charts {
+		next := charts[(i+1)%len(charts)]
+		previous := charts[(i+len(charts)-1)%len(charts)]
+
+		chart.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
+			switch e.Key() {
+			case tcell.KeyTab:
+				v.App.SetFocus(next)
+				return nil
+			case tcell.KeyBacktab:
+				v.App.SetFocus(previous)
+				return nil
+			}
+
+			return back(e)
+		})
+	}
+
+	v.PushScreen("library-stats", grid, screenKeys(libraryStatsKeys, exportKeys, backKeys)...)
+}
+
+// Create a scrollable table of names, counts and bars scaled to the largest count
+func createBarChart(v *models.View, title string, tallies []models.Tally) *tview.Table {
+	table := newTable(v).SetBorders(false)
+	table.SetBorder(true).SetTitle(title)
+
+	max := 0
+	for _, t := range tallies {
+		if t.Count > max {
+			max = t.Count
+		}
+	}
+
+	for i, t := range tallies {
+		table.SetCell(i, 0, tview.NewTableCell(padLeft(t.Name)).SetTextColor(v.Theme.Cell).SetMaxWidth(30))
+		table.SetCell(i, 1, tview.NewTableCell(padLeft(strconv.Itoa(t.Count))).SetTextColor(v.Theme.Header).SetAlign(tview.AlignRight))
+		table.SetCell(i, 2, tview.NewTableCell(padLeft(bar(t.Count, max, barWidth))).SetTextColor(v.Theme.Chart).SetExpansion(1))
+	}
+
+	if len(tallies) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell(padLeft("No data")).SetTextColor(v.Theme.Muted))
+	}
+
+	return table
+}
+
+// a bar of up to width blocks, proportional to count/max; any non-zero count gets at least one block
+func bar(count int, max int, width int) string {
+	if count <= 0 || max <= 0 {
+		return ""
+	}
+
+	n := count * width / max
+	if n == 0 {
+		n = 1
+	}
+
+	return strings.Repeat("█", n)
+}
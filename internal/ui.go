@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"fmt"
+
 	"github.com/ccb012100/go-playlist-search/internal/models"
 
 	"github.com/gdamore/tcell/v2"
@@ -9,20 +11,15 @@ import (
 )
 
 // Add key bindings for selecting list items.
-func AddListInputListener(l *tview.List) {
+func AddListInputListener(l *tview.List, km models.Keymap) {
 	l.SetInputCapture(func(e *tcell.EventKey) *tcell.EventKey {
-		switch e.Key() {
-		case tcell.KeyRune:
-			switch e.Rune() {
-			case rune('n'):
-				SelectNextListItem(l)
-			case 'p':
-				SelectPreviousListIten(l)
-			case 'j':
-				SelectNextListItem(l)
-			case 'k':
-				SelectPreviousListIten(l)
-			}
+		switch {
+		case km.Matches(models.ActionListNext, e):
+			SelectNextListItem(l)
+			return nil
+		case km.Matches(models.ActionListPrevious, e):
+			SelectPreviousListIten(l)
+			return nil
 		}
 
 		return e
@@ -49,47 +46,141 @@ func SelectPreviousListIten(l *tview.List) {
 
 func CreateMessageBar(v *models.View) {
 	v.MessageBar = tview.NewTextView().SetTextAlign(tview.AlignCenter).SetText("Message Bar")
-	v.MessageBar.SetBorder(true).SetBorderColor(tcell.ColorDarkGreen)
+	v.MessageBar.SetBorder(true).SetBorderColor(v.Theme.MessageBorder)
 }
 
 func CreateTitleBar(v *models.View) {
-	v.TitleBar = tview.NewTextView().SetTextAlign(tview.AlignCenter).SetText("Menu Bar")
-	v.TitleBar.SetBorder(true).SetBorderColor(tcell.ColorHotPink)
+	// dynamic colors for the breadcrumb
+	v.TitleBar = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter).SetText("Menu Bar")
+	v.TitleBar.SetBorder(true).SetBorderColor(v.Theme.TitleBorder)
 }
 
 func CreateViewGrid(v *models.View) {
+	if v.Theme.Name == "" {
+		v.Theme = models.Themes[models.DefaultTheme]
+	}
+
+	// before any widgets are created, since they take their default colors from tview.Styles
+	v.Theme.Apply()
+
 	CreateTitleBar(v)
 	CreateMessageBar(v)
 
-	v.List = tview.NewList()
-	v.List.SetBorder(true).SetBorderColor(tcell.ColorDarkRed).SetTitle("List")
-	AddListInputListener(v.List)
+	v.Pages = tview.NewPages()
+	v.History = models.NewHistory()
+
+	if v.Keymap == nil {
+		v.Keymap = models.DefaultKeymap()
+	}
 
 	v.Grid = tview.NewGrid().SetRows(4, 0, 4).SetColumns(0).SetBorders(true)
-	v.Grid.SetBorderColor(tcell.ColorMediumPurple)
+	v.Grid.SetBorderColor(v.Theme.Border)
 
 	// row 0: Menu Bar
 	v.Grid.AddItem(v.TitleBar, 0, 0, 1, 1, 0, 0, false).
 		// row 1: main content
-		AddItem(v.List, 1, 0, 1, 1, 0, 0, true).
+		AddItem(v.Pages, 1, 0, 1, 1, 0, 0, true).
 		// row 2: Message Bar
 		AddItem(v.MessageBar, 2, 0, 1, 1, 0, 0, false)
+
+	v.App.SetInputCapture(GlobalInputFunc(v))
+}
+
+// Create an empty List for a new screen.
+// Each screen gets its own List, so going back to a screen shows the List it displayed.
+func NewList(v *models.View) *tview.List {
+	l := newList(v)
+	l.SetBorder(true).SetBorderColor(v.Theme.ListBorder).SetTitle("List")
+	AddListInputListener(l, v.Keymap)
+
+	return l
+}
+
+// Create a List whose selected item is drawn in the Theme's colors
+func newList(v *models.View) *tview.List {
+	return tview.NewList().SetSelectedTextColor(v.Theme.SelectedText).SetSelectedBackgroundColor(v.Theme.SelectedBackground)
+}
+
+// Create a Table whose selected row is drawn in the Theme's colors
+func newTable(v *models.View) *tview.Table {
+	return tview.NewTable().SetSelectedStyle(v.Theme.SelectedStyle())
+}
+
+// Input capture for the keys that work on every screen: help, back and forward.
+// The help key shows the current screen's keys, then every key, then closes the help.
+// Unmodified characters are left to a focused input field, so that they can be searched for.
+func GlobalInputFunc(v *models.View) func(*tcell.EventKey) *tcell.EventKey {
+	// the modal displayed by ShowScreenHelp, if it's the one that was displayed last
+	var screenHelp tview.Primitive
+
+	return func(e *tcell.EventKey) *tcell.EventKey {
+		if _, typing := v.App.GetFocus().(*tview.InputField); typing && e.Key() == tcell.KeyRune && e.Modifiers()&tcell.ModAlt == 0 {
+			return e
+		}
+
+		switch {
+		case v.Keymap.Matches(models.ActionHelp, e):
+			switch modal := v.Modal(); {
+			case modal == nil:
+				ShowScreenHelp(v)
+				screenHelp = v.Modal()
+			case modal == screenHelp:
+				ShowKeymapHelp(v)
+			default:
+				v.CloseModal()
+			}
+
+			return nil
+		case v.Keymap.Matches(models.ActionBack, e):
+			v.Back()
+			return nil
+		case v.Keymap.Matches(models.ActionForward, e):
+			v.Forward()
+			return nil
+		}
+
+		return e
+	}
 }
 
+// keys of the main menu's items, for its help
+var mainMenuKeys = []models.ScreenAction{
+	{Action: models.ActionMenuPlaylists},
+	{Action: models.ActionMenuArtists},
+	{Action: models.ActionMenuAlbums},
+	{Action: models.ActionMenuSongs},
+	{Action: models.ActionMenuStarred},
+	{Action: models.ActionMenuDuplicates},
+	{Action: models.ActionMenuEverything},
+	{Action: models.ActionMenuStats},
+	{Action: models.ActionQuit, Description: "Quit"},
+}
+
+// Display the main menu, forgetting the screens displayed before it
 func GoToMainMenu(v *models.View) {
-	v.List.Clear().
-		AddItem("Playlists", "Search Playlists", 'a', func() { SearchForPlaylists(v) }).
-		AddItem("Artists", "Search Artists", 's', func() { SearchForArtists(v) }).
-		AddItem("Albums", "Search Albums", 'd', func() { SearchForAlbums(v) }).
-		AddItem("Songs", "Search Songs", 'f', func() { SearchForSongs(v) }).
-		AddItem("Starred", "Search Starred Playlists", 'j', func() { SearchStarredPlaylists(v) }).
-		AddItem("Duplicate Songs", "Show Duplicate Songs in Starred Playlists", 'k', func() { ShowDuplicateSongsinStarredPlaylists(v) })
+	v.ClearHistory()
+	v.UpdateTitleBar("Main Menu")
+
+	km := v.Keymap
 
-	AddQuitOption(v.List, func() { v.App.Stop() })
+	v.List = NewList(v)
+	v.List.
+		AddItem("Playlists", "Search Playlists", km.Shortcut(models.ActionMenuPlaylists), func() { SearchForPlaylists(v) }).
+		AddItem("Artists", "Search Artists", km.Shortcut(models.ActionMenuArtists), func() { SearchForArtists(v) }).
+		AddItem("Albums", "Search Albums", km.Shortcut(models.ActionMenuAlbums), func() { SearchForAlbums(v) }).
+		AddItem("Songs", "Search Songs", km.Shortcut(models.ActionMenuSongs), func() { SearchForSongs(v) }).
+		AddItem("Starred", "Search Starred Playlists", km.Shortcut(models.ActionMenuStarred), func() {
+			SelectPlaylistGroup(v, "Search", func(group models.PlaylistGroup) { SearchStarredPlaylists(v, group) })
+		}).
+		AddItem("Duplicate Songs", "Show Duplicate Songs in Starred, chosen or all Playlists", km.Shortcut(models.ActionMenuDuplicates), func() { ShowDuplicateSongOptions(v) }).
+		AddItem("Everything", "Search Artists, Albums, Songs and Playlists at once", km.Shortcut(models.ActionMenuEverything), func() { SearchForEverything(v) }).
+		AddItem("Statistics", "Show Library counts, top Artists and Albums, and Tracks added per month", km.Shortcut(models.ActionMenuStats), func() { ShowLibraryStats(v) })
 
-	v.List.SetTitle("Main Menu").SetBorderColor(tcell.ColorDarkRed)
+	AddQuitOption(v, v.List, func() { v.App.Stop() })
 
-	v.SetMainPanel(v.List)
+	v.List.SetTitle("Main Menu").SetBorderColor(v.Theme.ListBorder)
+
+	v.PushScreen("main-menu", v.List, screenKeys(listKeys, mainMenuKeys)...)
 }
 
 // convert ints into alphabetic characters,
@@ -99,35 +190,40 @@ func IntToAlpha(i int) rune {
 }
 
 // add a Quit option to the passed-in list
-func AddQuitOption(list *tview.List, f func()) {
-	list.AddItem("[red::b]Quit[-]", "[red::]Press q to exit[-]", 'q', f)
+func AddQuitOption(v *models.View, list *tview.List, f func()) {
+	list.AddItem(colorText(v.Theme.Quit, "b", "Quit"), colorText(v.Theme.Quit, "", fmt.Sprintf("Press %s to exit", v.Keymap.Label(models.ActionQuit))), v.Keymap.Shortcut(models.ActionQuit), f)
 }
 
 // add a Quit to Home Page option to the passed-in list
 func AddQuitToHomeOption(list *tview.List, v *models.View) {
-	AddQuitOption(list, func() {
+	AddQuitOption(v, list, func() {
 		GoToMainMenu(v)
 	})
 }
 
 // add a Reset Page option to the passed-in list
-func AddResetOption(list *tview.List, f func()) {
-	list.AddItem("[yellow::b]Reset[-]", "[yellow::]Press r to reset this page[-]", 'r', f)
+func AddResetOption(v *models.View, list *tview.List, f func()) {
+	list.AddItem(colorText(v.Theme.Reset, "b", "Reset"), colorText(v.Theme.Reset, "", fmt.Sprintf("Press %s to reset this page", v.Keymap.Label(models.ActionReset))), v.Keymap.Shortcut(models.ActionReset), f)
 }
 
-func BackToViewListFunc(v *models.View) func(*tcell.EventKey) *tcell.EventKey {
+// Input capture that returns to the previous screen on Esc or the Back action's keys;
+// other keys pass through, so that e.g. j/k still move a table's selection
+func BackFunc(v *models.View) func(*tcell.EventKey) *tcell.EventKey {
 	return func(e *tcell.EventKey) *tcell.EventKey {
-		switch e.Key() {
-		case tcell.KeyESC:
-			v.SetMainPanel(v.List)
-		case tcell.KeyRune:
-			v.SetMainPanel(v.List)
+		if e.Key() == tcell.KeyESC || v.Keymap.Matches(models.ActionBack, e) {
+			v.Back()
+			return nil
 		}
 
 		return e
 	}
 }
 
+// Wrap the text in tview tags for the color and attributes, e.g. "b" for bold
+func colorText(c tcell.Color, attributes string, text string) string {
+	return fmt.Sprintf("[%s::%s]%s[-::-]", models.ColorTag(c), attributes, text)
+}
+
 func padLeft(s string) string {
 	return "  " + s
 }
@@ -135,3 +231,26 @@ func padLeft(s string) string {
 func padRight(s string) string {
 	return s + "  "
 }
+
+// format a duration in milliseconds as "h:mm:ss", or "m:ss" if it's under an hour
+func formatDuration(ms int) string {
+	seconds := ms / 1000
+	hours := seconds / 3600
+	minutes := (seconds % 3600) / 60
+	seconds = seconds % 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// trim a timestamp such as "2021-05-01T10:00:00Z" down to its date
+func formatDate(timestamp string) string {
+	if len(timestamp) < 10 {
+		return timestamp
+	}
+
+	return timestamp[:10]
+}
@@ -1,21 +1,65 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/ccb012100/go-playlist-search/config"
 	"github.com/ccb012100/go-playlist-search/internal"
+	"github.com/ccb012100/go-playlist-search/internal/cli"
+	"github.com/ccb012100/go-playlist-search/internal/db"
+	"github.com/ccb012100/go-playlist-search/internal/format"
 	"github.com/ccb012100/go-playlist-search/internal/models"
-
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/rivo/tview"
 )
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-format json|csv|tsv|table|markdown|m3u|xspf] [-group name] [command [arguments] [flags]]\n\n", os.Args[0])
+		cli.Usage(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+		flag.PrintDefaults()
+	}
+	formatName := flag.String("format", string(format.Table), cli.FormatUsage)
+	groupName := flag.String("group", "", cli.GroupUsage)
+	flag.Parse()
+
+	outputFormat, err := format.Parse(*formatName)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cli.ExitError)
+	}
+
 	conf := config.SetConfig()
 
+	repo, err := db.Open(conf.DBFilePath)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open DB '%s': %v\n", conf.DBFilePath, err)
+		os.Exit(cli.ExitError)
+	}
+
+	// run a subcommand non-interactively instead of starting the UI
+	if flag.NArg() > 0 {
+		opts := cli.Options{Format: outputFormat, Groups: conf.PlaylistGroups, Group: *groupName}
+		code := cli.Run(flag.Args(), opts, repo, os.Stdout, os.Stderr)
+		repo.Close()
+		os.Exit(code)
+	}
+
+	// the Quit option stops the App, after which the DB connection is closed
+	defer repo.Close()
+
 	// create main View
 	view := &models.View{
-		DB:  conf.DBFilePath,
-		App: tview.NewApplication().EnableMouse(true),
+		DB:             repo,
+		App:            tview.NewApplication().EnableMouse(true),
+		ExportDir:      conf.ExportDir,
+		PlaylistGroups: conf.PlaylistGroups,
+		Keymap:         conf.Keymap,
+		Theme:          conf.Theme,
 	}
 
 	internal.CreateViewGrid(view)